@@ -44,8 +44,9 @@ func (p *Program) String() string {
 	return sb.String()
 }
 
+// let x = 5 or const x = 5
 type LetStatement struct {
-	Token token.Token // token.LET
+	Token token.Token // token.LET or token.CONST
 	Name  *Identifier
 	Value Expression
 }
@@ -69,6 +70,17 @@ func (ls *LetStatement) String() string {
 	return sb.String()
 }
 
+// fn name(params) { ... }, binds the function to its name like a let statement.
+type FunctionStatement struct {
+	Token    token.Token // token.FUNCTION
+	Function *FunctionLiteral
+}
+
+// Implements Statement
+func (fs *FunctionStatement) statementNode()       {}
+func (fs *FunctionStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *FunctionStatement) String() string       { return fs.Function.String() }
+
 type Identifier struct {
 	Token token.Token // token.IDENTIFIER
 	Value string
@@ -127,14 +139,50 @@ func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
 type StringLiteral struct {
-	Token token.Token // token.STRING
+	Token token.Token // token.STRING, token.RAW_STRING or token.MULTILINE_STRING
 	Value string
 }
 
 // Implements Expression
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
-func (sl *StringLiteral) String() string       { return sl.Token.Literal }
+func (sl *StringLiteral) String() string {
+	// Use the same delimiters as the source, so the output parses back to the same value.
+	switch sl.Token.Type {
+	case token.RAW_STRING:
+		return "`" + sl.Value + "`"
+	case token.MULTILINE_STRING:
+		// The lexer drops the first and last line breaks, so the value is kept as is.
+		return `"""` + "\n" + sl.Value + "\n" + `"""`
+	default:
+		return `"` + sl.Value + `"`
+	}
+}
+
+// A string with embedded expressions, like "hello ${name}"
+type InterpolatedString struct {
+	Token token.Token  // token.STRING_HEAD
+	Parts []Expression // Either *StringLiteral or the embedded expressions
+}
+
+// Implements Expression
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(`"`)
+	for _, part := range is.Parts {
+		if str, ok := part.(*StringLiteral); ok {
+			sb.WriteString(str.Value)
+		} else {
+			sb.WriteString("${" + part.String() + "}")
+		}
+	}
+	sb.WriteString(`"`)
+
+	return sb.String()
+}
 
 type ArrayLiteral struct {
 	Token    token.Token // token.LBRACKET
@@ -160,8 +208,13 @@ func (al *ArrayLiteral) String() string {
 }
 
 type HashLiteral struct {
-	Token token.Token // token.LBRACE
-	Pairs map[Expression]Expression
+	Token token.Token       // token.LBRACE
+	Pairs []HashLiteralPair // In source order
+}
+
+type HashLiteralPair struct {
+	Key   Expression
+	Value Expression
 }
 
 // Implements Expression
@@ -171,8 +224,8 @@ func (hl *HashLiteral) String() string {
 	var sb strings.Builder
 
 	pairs := make([]string, 0, len(hl.Pairs))
-	for k, v := range hl.Pairs {
-		pairs = append(pairs, k.String()+":"+v.String())
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
 	}
 
 	sb.WriteByte('{')
@@ -264,7 +317,8 @@ func (bs *BlockStatement) String() string {
 }
 
 type FunctionLiteral struct {
-	Token      token.Token // token.FUNCTION
+	Token      token.Token // token.FUNCTION, or token.ARROW for x => x
+	Name       *Identifier // nil for anonymous functions
 	Parameters []*Identifier
 	Body       *BlockStatement
 }
@@ -280,7 +334,11 @@ func (fl *FunctionLiteral) String() string {
 		params = append(params, p.String())
 	}
 
-	sb.WriteString("fn(")
+	sb.WriteString("fn")
+	if fl.Name != nil {
+		sb.WriteString(" " + fl.Name.String())
+	}
+	sb.WriteString("(")
 	sb.WriteString(strings.Join(params, ", "))
 	sb.WriteString(")")
 	sb.WriteString(fl.Body.String())
@@ -325,3 +383,134 @@ func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
 func (ie *IndexExpression) String() string {
 	return "(" + ie.Left.String() + "[" + ie.Index.String() + "])"
 }
+
+type SliceExpression struct {
+	Token token.Token // token.LBRACKET
+	Left  Expression
+	Start Expression // nil when omitted, same for End and Step
+	End   Expression
+	Step  Expression
+}
+
+// Implements Expression
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("(")
+	sb.WriteString(se.Left.String())
+	sb.WriteString("[")
+	if se.Start != nil {
+		sb.WriteString(se.Start.String())
+	}
+	sb.WriteString(":")
+	if se.End != nil {
+		sb.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		sb.WriteString(":")
+		sb.WriteString(se.Step.String())
+	}
+	sb.WriteString("])")
+
+	return sb.String()
+}
+
+// cond ? a : b
+type ConditionalExpression struct {
+	Token       token.Token // token.QUESTION
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+// Implements Expression
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) String() string {
+	return "(" + ce.Condition.String() + " ? " + ce.Consequence.String() + " : " +
+		ce.Alternative.String() + ")"
+}
+
+// a ?? b, b is only evaluated if a is null
+type NullCoalescingExpression struct {
+	Token token.Token // token.NULL_COALESCE
+	Left  Expression
+	Right Expression
+}
+
+// Implements Expression
+func (nc *NullCoalescingExpression) expressionNode()      {}
+func (nc *NullCoalescingExpression) TokenLiteral() string { return nc.Token.Literal }
+func (nc *NullCoalescingExpression) String() string {
+	return "(" + nc.Left.String() + " ?? " + nc.Right.String() + ")"
+}
+
+// a?.[k], null if a is null
+type OptionalIndexExpression struct {
+	Token token.Token // token.OPTIONAL_CHAIN
+	Left  Expression
+	Index Expression
+}
+
+// Implements Expression
+func (oi *OptionalIndexExpression) expressionNode()      {}
+func (oi *OptionalIndexExpression) TokenLiteral() string { return oi.Token.Literal }
+func (oi *OptionalIndexExpression) String() string {
+	return "(" + oi.Left.String() + "?.[" + oi.Index.String() + "])"
+}
+
+// f?.(x), null if f is null
+type OptionalCallExpression struct {
+	Token     token.Token // token.OPTIONAL_CHAIN
+	Function  Expression
+	Arguments []Expression
+}
+
+// Implements Expression
+func (oc *OptionalCallExpression) expressionNode()      {}
+func (oc *OptionalCallExpression) TokenLiteral() string { return oc.Token.Literal }
+func (oc *OptionalCallExpression) String() string {
+	var sb strings.Builder
+
+	args := make([]string, 0, len(oc.Arguments))
+	for _, a := range oc.Arguments {
+		args = append(args, a.String())
+	}
+
+	sb.WriteString(oc.Function.String())
+	sb.WriteString("?.(")
+	sb.WriteString(strings.Join(args, ", "))
+	sb.WriteString(")")
+
+	return sb.String()
+}
+
+// xs |> map(f), calls the right side with the left value as its first argument.
+type PipeExpression struct {
+	Token token.Token // token.PIPE_FORWARD
+	Left  Expression
+	Right Expression // Usually a *CallExpression, otherwise it's called with just Left
+}
+
+// Implements Expression
+func (pe *PipeExpression) expressionNode()      {}
+func (pe *PipeExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PipeExpression) String() string {
+	return "(" + pe.Left.String() + " |> " + pe.Right.String() + ")"
+}
+
+// x = 5 or xs[i] = 5
+type AssignExpression struct {
+	Token  token.Token // token.ASSIGN
+	Target Expression  // *Identifier or *IndexExpression
+	Value  Expression
+}
+
+// Implements Expression
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) String() string {
+	return "(" + ae.Target.String() + " = " + ae.Value.String() + ")"
+}
@@ -2,12 +2,43 @@ package evaluator
 
 import (
 	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/ManuelGarciaF/go-interpreter/object"
 )
 
+// DefaultBuiltins returns a new registry with the standard builtins, which the caller is
+// free to modify.
+func DefaultBuiltins() *object.BuiltinRegistry {
+	r := object.NewBuiltinRegistry()
+	for name, b := range builtins {
+		r.Register(name, b.Fn)
+	}
+	for name, b := range stringBuiltins {
+		r.Register(name, b.Fn)
+	}
+	for name, b := range arrayBuiltins {
+		r.Register(name, b.Fn)
+	}
+	for name, b := range hashBuiltins {
+		r.Register(name, b.Fn)
+	}
+	for name, b := range typeBuiltins {
+		r.Register(name, b.Fn)
+	}
+	for name, b := range formatBuiltins {
+		r.Register(name, b.Fn)
+	}
+	for name, b := range fsBuiltins {
+		r.RegisterUnsafe(name, b.Fn)
+	}
+	return r
+}
+
 var builtins = map[string]*object.Builtin{
-	"len": {Fn: func(args ...object.Object) object.Object {
+	"len": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
 		if len(args) != 1 {
 			return newError("wrong number of arguments. got=%d, want=1",
 				len(args))
@@ -15,14 +46,17 @@ var builtins = map[string]*object.Builtin{
 
 		switch arg := args[0].(type) {
 		case *object.String:
-			return nativeToIntegerObject(len(arg.Value))
+			// Counts characters, like string indexing and slicing.
+			return nativeToIntegerObject(utf8.RuneCountInString(arg.Value))
 		case *object.Array:
 			return nativeToIntegerObject(len(arg.Elements))
+		case *object.Hash:
+			return nativeToIntegerObject(arg.Len())
 		default:
 			return newError("argument to `len` not supported, got %s", arg.Type())
 		}
 	}},
-	"first": {Fn: func(args ...object.Object) object.Object {
+	"first": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
 		if len(args) != 1 {
 			return newError("wrong number of arguments. got=%d, want=1",
 				len(args))
@@ -39,7 +73,7 @@ var builtins = map[string]*object.Builtin{
 		return arr.Elements[0]
 
 	}},
-	"last": {Fn: func(args ...object.Object) object.Object {
+	"last": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
 		if len(args) != 1 {
 			return newError("wrong number of arguments. got=%d, want=1",
 				len(args))
@@ -55,7 +89,7 @@ var builtins = map[string]*object.Builtin{
 
 		return arr.Elements[len(arr.Elements)-1]
 	}},
-	"tail": {Fn: func(args ...object.Object) object.Object {
+	"tail": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
 		if len(args) != 1 {
 			return newError("wrong number of arguments. got=%d, want=1",
 				len(args))
@@ -74,7 +108,7 @@ var builtins = map[string]*object.Builtin{
 		copy(newElements, arr.Elements[1:length])
 		return &object.Array{Elements: newElements}
 	}},
-	"push": {Fn: func(args ...object.Object) object.Object {
+	"push": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
 		if len(args) != 2 {
 			return newError("wrong number of arguments. got=%d, want=2",
 				len(args))
@@ -91,11 +125,112 @@ var builtins = map[string]*object.Builtin{
 
 		return &object.Array{Elements: newElements}
 	}},
-	"puts": {Fn: func(args ...object.Object) object.Object {
+	"freeze": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		// Returns the same value, now frozen.
+		object.Freeze(args[0])
+		return args[0]
+	}},
+	"puts": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		for _, arg := range args {
+			fmt.Fprintln(ctx.Stdout, arg.Inspect())
+		}
+
+		return NULL
+	}},
+	"print": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		// Like puts, but without adding newlines.
 		for _, arg := range args {
-			fmt.Println(arg.Inspect())
+			fmt.Fprint(ctx.Stdout, arg.Inspect())
 		}
 
 		return NULL
 	}},
+	"eprint": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		for _, arg := range args {
+			fmt.Fprint(ctx.Stderr, arg.Inspect())
+		}
+
+		return NULL
+	}},
+	"input": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) > 1 {
+			return newError("wrong number of arguments. got=%d, want=0 or 1",
+				len(args))
+		}
+		if len(args) == 1 {
+			prompt, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `input` must be STRING, got %s", args[0].Type())
+			}
+			fmt.Fprint(ctx.Stdout, prompt.Value)
+		}
+
+		return readLine(ctx)
+	}},
+	"readLine": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError("wrong number of arguments. got=%d, want=0",
+				len(args))
+		}
+
+		return readLine(ctx)
+	}},
+	"readAll": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 0 {
+			return newError("wrong number of arguments. got=%d, want=0",
+				len(args))
+		}
+
+		contents, err := io.ReadAll(ctx.StdinReader())
+		if err != nil {
+			return newError("could not read input: %s", err)
+		}
+
+		return &object.String{Value: string(contents)}
+	}},
+}
+
+// Checks the number and types of the arguments of a builtin, returns nil if they are correct.
+func checkArgs(name string, args []object.Object, types ...object.ObjectType) *object.Error {
+	if len(args) != len(types) {
+		return newError("wrong number of arguments. got=%d, want=%d",
+			len(args), len(types))
+	}
+
+	for i, t := range types {
+		// Builtins can be passed wherever a function is expected.
+		if args[i].Type() == t || (t == object.FUNCTION_OBJ && args[i].Type() == object.BUILTIN_OBJ) {
+			continue
+		}
+		// Same wording as the hand written checks, like "first argument to `push`".
+		if len(types) == 1 {
+			return newError("argument to `%s` must be %s, got %s", name, t, args[i].Type())
+		}
+		return newError("%s argument to `%s` must be %s, got %s",
+			ordinals[i], name, t, args[i].Type())
+	}
+
+	return nil
+}
+
+var ordinals = []string{"first", "second", "third", "fourth", "fifth"}
+
+// Reads a line from stdin without the line terminator, returns NULL at EOF.
+func readLine(ctx *object.Context) object.Object {
+	line, err := ctx.StdinReader().ReadString('\n')
+	if err == io.EOF && line == "" {
+		return NULL
+	}
+	if err != nil && err != io.EOF {
+		return newError("could not read input: %s", err)
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return &object.String{Value: line}
 }
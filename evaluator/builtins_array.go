@@ -0,0 +1,320 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/ManuelGarciaF/go-interpreter/object"
+)
+
+// The largest array that `range` is allowed to build.
+const maxRangeSize = 1 << 24
+
+// Array builtins that take functions call them through applyFunction, so they work with
+// both monkey functions and builtins. They never modify their arguments.
+var arrayBuiltins = map[string]*object.Builtin{
+	"map": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("map", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		results := make([]object.Object, 0, len(arr.Elements))
+		for _, el := range arr.Elements {
+			result := applyFunction(args[1], []object.Object{el}, ctx)
+			if isError(result) {
+				return result
+			}
+			results = append(results, result)
+		}
+
+		return &object.Array{Elements: results}
+	}},
+	"filter": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("filter", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		results := make([]object.Object, 0)
+		for _, el := range arr.Elements {
+			keep := applyFunction(args[1], []object.Object{el}, ctx)
+			if isError(keep) {
+				return keep
+			}
+			if isTruthy(keep) {
+				results = append(results, el)
+			}
+		}
+
+		return &object.Array{Elements: results}
+	}},
+	"reduce": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 3 {
+			return newError("wrong number of arguments. got=%d, want=3",
+				len(args))
+		}
+		if err := checkArgs("reduce", args[:2], object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		// The third argument is the initial value of the accumulator.
+		acc := args[2]
+		for _, el := range arr.Elements {
+			acc = applyFunction(args[1], []object.Object{acc, el}, ctx)
+			if isError(acc) {
+				return acc
+			}
+		}
+
+		return acc
+	}},
+	"each": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("each", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		for _, el := range arr.Elements {
+			result := applyFunction(args[1], []object.Object{el}, ctx)
+			if isError(result) {
+				return result
+			}
+		}
+
+		return NULL
+	}},
+	"find": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("find", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		for _, el := range arr.Elements {
+			found := applyFunction(args[1], []object.Object{el}, ctx)
+			if isError(found) {
+				return found
+			}
+			if isTruthy(found) {
+				return el
+			}
+		}
+
+		return NULL
+	}},
+	"any": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("any", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		for _, el := range arr.Elements {
+			result := applyFunction(args[1], []object.Object{el}, ctx)
+			if isError(result) {
+				return result
+			}
+			if isTruthy(result) {
+				return TRUE
+			}
+		}
+
+		return FALSE
+	}},
+	"all": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("all", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		for _, el := range arr.Elements {
+			result := applyFunction(args[1], []object.Object{el}, ctx)
+			if isError(result) {
+				return result
+			}
+			if !isTruthy(result) {
+				return FALSE
+			}
+		}
+
+		return TRUE
+	}},
+	"sort": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		// The comparator is optional, it must return true if its first argument goes before
+		// the second.
+		if len(args) == 1 {
+			if err := checkArgs("sort", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+		} else {
+			if err := checkArgs("sort", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+		}
+		arr := args[0].(*object.Array)
+
+		sorted := make([]object.Object, len(arr.Elements))
+		copy(sorted, arr.Elements)
+
+		// sort.SliceStable can't be stopped, so we keep the first error and return it
+		// at the end.
+		var sortErr object.Object
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if sortErr != nil {
+				return false
+			}
+
+			var less object.Object
+			if len(args) == 1 {
+				less = defaultLess(sorted[i], sorted[j])
+			} else {
+				less = applyFunction(args[1], []object.Object{sorted[i], sorted[j]}, ctx)
+			}
+
+			if isError(less) {
+				sortErr = less
+				return false
+			}
+			return isTruthy(less)
+		})
+		if sortErr != nil {
+			return sortErr
+		}
+
+		return &object.Array{Elements: sorted}
+	}},
+	"reverse": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("reverse", args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		length := len(arr.Elements)
+		reversed := make([]object.Object, length)
+		for i, el := range arr.Elements {
+			reversed[length-1-i] = el
+		}
+
+		return &object.Array{Elements: reversed}
+	}},
+	"zip": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("zip", args, object.ARRAY_OBJ, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		left := args[0].(*object.Array).Elements
+		right := args[1].(*object.Array).Elements
+
+		// Extra elements in the longest array are ignored.
+		length := min(len(left), len(right))
+		pairs := make([]object.Object, 0, length)
+		for i := 0; i < length; i++ {
+			pairs = append(pairs, &object.Array{Elements: []object.Object{left[i], right[i]}})
+		}
+
+		return &object.Array{Elements: pairs}
+	}},
+	"flatten": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("flatten", args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		// Only flattens one level, like [[1], [[2]]] -> [1, [2]].
+		flat := make([]object.Object, 0, len(arr.Elements))
+		for _, el := range arr.Elements {
+			if inner, ok := el.(*object.Array); ok {
+				flat = append(flat, inner.Elements...)
+			} else {
+				flat = append(flat, el)
+			}
+		}
+
+		return &object.Array{Elements: flat}
+	}},
+	"uniq": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("uniq", args, object.ARRAY_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+
+		// Keeps the first occurrence of each element.
+		seen := object.NewHash(len(arr.Elements))
+		unique := make([]object.Object, 0)
+		for _, el := range arr.Elements {
+			key, ok := object.AsHashable(el)
+			if !ok {
+				return newError("elements of the argument to `uniq` must be hashable, got %s",
+					el.Type())
+			}
+			if _, ok := seen.Get(key); !ok {
+				seen.Set(key, TRUE)
+				unique = append(unique, el)
+			}
+		}
+
+		return &object.Array{Elements: unique}
+	}},
+	"range": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		// Like python, range(end), range(start, end) or range(start, end, step).
+		var start, end, step int64 = 0, 0, 1
+		switch len(args) {
+		case 1:
+			if err := checkArgs("range", args, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+			end = args[0].(*object.Integer).Value
+		case 2:
+			if err := checkArgs("range", args, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+			start = args[0].(*object.Integer).Value
+			end = args[1].(*object.Integer).Value
+		case 3:
+			err := checkArgs("range", args, object.INTEGER_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ)
+			if err != nil {
+				return err
+			}
+			start = args[0].(*object.Integer).Value
+			end = args[1].(*object.Integer).Value
+			step = args[2].(*object.Integer).Value
+		default:
+			return newError("wrong number of arguments. got=%d, want=1 to 3",
+				len(args))
+		}
+
+		if step == 0 {
+			return newError("third argument to `range` must not be 0")
+		}
+
+		// Count the elements up front, adding the step until passing the end could
+		// overflow. The differences are computed as uint64 so they can't overflow either.
+		var count uint64
+		if step > 0 && start < end {
+			count = (uint64(end)-uint64(start)-1)/uint64(step) + 1
+		} else if step < 0 && start > end {
+			count = (uint64(start)-uint64(end)-1)/uint64(-step) + 1
+		}
+
+		if count > maxRangeSize {
+			return newError("result of `range` is too large, the limit is %d elements", maxRangeSize)
+		}
+
+		elements := make([]object.Object, 0, count)
+		for i := uint64(0); i < count; i++ {
+			elements = append(elements, &object.Integer{Value: start + int64(i)*step})
+		}
+
+		return &object.Array{Elements: elements}
+	}},
+}
+
+// Ordering used by sort without a comparator, only integers and strings can be compared.
+func defaultLess(a, b object.Object) object.Object {
+	switch {
+	case a.Type() == object.INTEGER_OBJ && b.Type() == object.INTEGER_OBJ:
+		return nativeToBooleanObject(a.(*object.Integer).Value < b.(*object.Integer).Value)
+	case a.Type() == object.STRING_OBJ && b.Type() == object.STRING_OBJ:
+		return nativeToBooleanObject(a.(*object.String).Value < b.(*object.String).Value)
+	default:
+		return newError("`sort` can't compare %s and %s without a comparator", a.Type(), b.Type())
+	}
+}
@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ManuelGarciaF/go-interpreter/object"
+)
+
+var formatBuiltins = map[string]*object.Builtin{
+	"format": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		format, errObj := formatArgs("format", args)
+		if errObj != nil {
+			return errObj
+		}
+
+		formatted, errObj := formatObjects(format, args[1:])
+		if errObj != nil {
+			return errObj
+		}
+
+		return &object.String{Value: formatted}
+	}},
+	"printf": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		format, errObj := formatArgs("printf", args)
+		if errObj != nil {
+			return errObj
+		}
+
+		formatted, errObj := formatObjects(format, args[1:])
+		if errObj != nil {
+			return errObj
+		}
+
+		// Like print, no newline is added.
+		fmt.Fprint(ctx.Stdout, formatted)
+		return NULL
+	}},
+}
+
+// Checks that there is a format string as the first argument.
+func formatArgs(name string, args []object.Object) (string, *object.Error) {
+	if len(args) == 0 {
+		return "", newError("wrong number of arguments. got=0, want at least 1")
+	}
+	format, ok := args[0].(*object.String)
+	if !ok {
+		return "", newError("first argument to `%s` must be STRING, got %s", name, args[0].Type())
+	}
+	return format.Value, nil
+}
+
+// Formats args according to format, which uses a subset of Go's verbs:
+//
+//	%d  INTEGER in base 10
+//	%s  any object, strings without quotes (like `str`)
+//	%v  any object, as shown by the REPL
+//	%q  STRING, quoted and escaped
+//	%x  INTEGER in base 16, or the bytes of a STRING. %X uses upper case
+//	%f  FLOAT or INTEGER in decimal notation
+//	%%  a literal percent sign
+//
+// Verbs accept Go's flags, width and precision, like "%-8s" or "%08.3f". A verb used with
+// the wrong type is an error, instead of Go's "%!d(...)" output.
+func formatObjects(format string, args []object.Object) (string, *object.Error) {
+	var sb strings.Builder
+	argIndex := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+
+		// Read the flags, width and precision up to the verb.
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) != -1 {
+			i++
+		}
+		if i >= len(format) {
+			return "", newError("format: incomplete verb %q at the end of the format string",
+				format[start:])
+		}
+		spec := format[start:i]
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size - 1
+
+		if verb == '%' {
+			sb.WriteByte('%')
+			continue
+		}
+
+		if argIndex >= len(args) {
+			return "", newError("format: missing argument for %s%c", spec, verb)
+		}
+		arg := args[argIndex]
+		argIndex++
+
+		formatted, errObj := formatVerb(spec, verb, arg)
+		if errObj != nil {
+			return "", errObj
+		}
+		sb.WriteString(formatted)
+	}
+
+	if argIndex < len(args) {
+		return "", newError("format: too many arguments. got=%d, used=%d", len(args), argIndex)
+	}
+
+	return sb.String(), nil
+}
+
+// Formats a single argument, spec contains the '%' and any flags.
+func formatVerb(spec string, verb rune, arg object.Object) (string, *object.Error) {
+	goFormat := spec + string(verb)
+
+	switch verb {
+	case 'd':
+		if integer, ok := arg.(*object.Integer); ok {
+			return fmt.Sprintf(goFormat, integer.Value), nil
+		}
+	case 's':
+		return fmt.Sprintf(goFormat, displayString(arg)), nil
+	case 'v':
+		return fmt.Sprintf(spec+"s", arg.Inspect()), nil
+	case 'q':
+		if str, ok := arg.(*object.String); ok {
+			return fmt.Sprintf(goFormat, str.Value), nil
+		}
+	case 'x', 'X':
+		switch arg := arg.(type) {
+		case *object.Integer:
+			return fmt.Sprintf(goFormat, arg.Value), nil
+		case *object.String:
+			return fmt.Sprintf(goFormat, arg.Value), nil
+		}
+	case 'f':
+		if isNumber(arg) {
+			return fmt.Sprintf(goFormat, toFloat(arg)), nil
+		}
+	default:
+		return "", newError("format: unknown verb %s", goFormat)
+	}
+
+	return "", newError("format: %s can't format %s", goFormat, arg.Type())
+}
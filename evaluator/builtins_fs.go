@@ -0,0 +1,142 @@
+package evaluator
+
+import (
+	"os"
+
+	"github.com/ManuelGarciaF/go-interpreter/object"
+)
+
+// File system builtins, they are registered as unsafe so sandboxes don't get them, and
+// check the context's FSPolicy before every access.
+var fsBuiltins = map[string]*object.Builtin{
+	"readFile": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		path, errObj := pathArgument("readFile", ctx, false, args, 1)
+		if errObj != nil {
+			return errObj
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return newError("could not read file: %s", err)
+		}
+
+		return &object.String{Value: string(contents)}
+	}},
+	"writeFile": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		path, errObj := pathArgument("writeFile", ctx, true, args, 2)
+		if errObj != nil {
+			return errObj
+		}
+		contents, ok := args[1].(*object.String)
+		if !ok {
+			return newError("second argument to `writeFile` must be STRING, got %s", args[1].Type())
+		}
+
+		if err := os.WriteFile(path, []byte(contents.Value), 0o644); err != nil {
+			return newError("could not write file: %s", err)
+		}
+
+		return NULL
+	}},
+	"appendFile": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		path, errObj := pathArgument("appendFile", ctx, true, args, 2)
+		if errObj != nil {
+			return errObj
+		}
+		contents, ok := args[1].(*object.String)
+		if !ok {
+			return newError("second argument to `appendFile` must be STRING, got %s", args[1].Type())
+		}
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return newError("could not open file: %s", err)
+		}
+		defer f.Close()
+
+		if _, err := f.WriteString(contents.Value); err != nil {
+			return newError("could not write file: %s", err)
+		}
+
+		return NULL
+	}},
+	"listDir": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		path, errObj := pathArgument("listDir", ctx, false, args, 1)
+		if errObj != nil {
+			return errObj
+		}
+
+		// ReadDir already sorts the entries by name.
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return newError("could not list directory: %s", err)
+		}
+
+		names := make([]object.Object, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, &object.String{Value: e.Name()})
+		}
+
+		return &object.Array{Elements: names}
+	}},
+	"exists": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		path, errObj := pathArgument("exists", ctx, false, args, 1)
+		if errObj != nil {
+			return errObj
+		}
+
+		_, err := os.Stat(path)
+		return nativeToBooleanObject(err == nil)
+	}},
+	"remove": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		path, errObj := pathArgument("remove", ctx, true, args, 1)
+		if errObj != nil {
+			return errObj
+		}
+
+		// Only removes files and empty directories, to avoid accidents.
+		if err := os.Remove(path); err != nil {
+			return newError("could not remove: %s", err)
+		}
+
+		return NULL
+	}},
+	"mkdir": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		path, errObj := pathArgument("mkdir", ctx, true, args, 1)
+		if errObj != nil {
+			return errObj
+		}
+
+		// Also creates the missing parents.
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return newError("could not create directory: %s", err)
+		}
+
+		return NULL
+	}},
+}
+
+// Checks the number of arguments, that the first one is a path and that the policy
+// allows accessing it.
+func pathArgument(
+	name string,
+	ctx *object.Context,
+	write bool,
+	args []object.Object,
+	want int,
+) (string, *object.Error) {
+	if len(args) != want {
+		return "", newError("wrong number of arguments. got=%d, want=%d",
+			len(args), want)
+	}
+	path, ok := args[0].(*object.String)
+	if !ok {
+		return "", newError("first argument to `%s` must be STRING, got %s", name, args[0].Type())
+	}
+
+	if err := ctx.FS.Check(path.Value, write); err != nil {
+		return "", newError("%s", err)
+	}
+
+	return path.Value, nil
+}
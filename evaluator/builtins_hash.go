@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"github.com/ManuelGarciaF/go-interpreter/object"
+)
+
+var hashBuiltins = map[string]*object.Builtin{
+	"keys": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("keys", args, object.HASH_OBJ); err != nil {
+			return err
+		}
+
+		pairs := args[0].(*object.Hash).Pairs()
+		keys := make([]object.Object, 0, len(pairs))
+		for _, pair := range pairs {
+			keys = append(keys, pair.Key)
+		}
+
+		return &object.Array{Elements: keys}
+	}},
+	"values": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("values", args, object.HASH_OBJ); err != nil {
+			return err
+		}
+
+		pairs := args[0].(*object.Hash).Pairs()
+		values := make([]object.Object, 0, len(pairs))
+		for _, pair := range pairs {
+			values = append(values, pair.Value)
+		}
+
+		return &object.Array{Elements: values}
+	}},
+	"entries": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("entries", args, object.HASH_OBJ); err != nil {
+			return err
+		}
+
+		// Each entry is a [key, value] array.
+		pairs := args[0].(*object.Hash).Pairs()
+		entries := make([]object.Object, 0, len(pairs))
+		for _, pair := range pairs {
+			entries = append(entries, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+		}
+
+		return &object.Array{Elements: entries}
+	}},
+	"has": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments. got=%d, want=2",
+				len(args))
+		}
+		hash, ok := args[0].(*object.Hash)
+		if !ok {
+			return newError("first argument to `has` must be HASH, got %s", args[0].Type())
+		}
+		key, ok := object.AsHashable(args[1])
+		if !ok {
+			return newError("unusable as hash key: %s", args[1].Type())
+		}
+
+		_, ok = hash.Get(key)
+		return nativeToBooleanObject(ok)
+	}},
+	"delete": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return newError("wrong number of arguments. got=%d, want=2",
+				len(args))
+		}
+		hash, ok := args[0].(*object.Hash)
+		if !ok {
+			return newError("first argument to `delete` must be HASH, got %s", args[0].Type())
+		}
+		key, ok := object.AsHashable(args[1])
+		if !ok {
+			return newError("unusable as hash key: %s", args[1].Type())
+		}
+
+		// The original hash is left untouched.
+		result := hash.Copy()
+		result.Delete(key)
+
+		return result
+	}},
+	"merge": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("merge", args, object.HASH_OBJ, object.HASH_OBJ); err != nil {
+			return err
+		}
+		left := args[0].(*object.Hash)
+		right := args[1].(*object.Hash)
+
+		// On conflicts, the values of the second hash win, but the keys keep their position.
+		result := left.Copy()
+		for _, pair := range right.Pairs() {
+			result.Set(pair.Key.(object.Hashable), pair.Value)
+		}
+
+		return result
+	}},
+}
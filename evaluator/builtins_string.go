@@ -0,0 +1,171 @@
+package evaluator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ManuelGarciaF/go-interpreter/object"
+)
+
+// The largest string that `repeat` is allowed to build.
+const maxStringSize = 1 << 30
+
+var stringBuiltins = map[string]*object.Builtin{
+	"split": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("split", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		str := args[0].(*object.String).Value
+		sep := args[1].(*object.String).Value
+
+		return nativeToStringArray(strings.Split(str, sep))
+	}},
+	"join": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("join", args, object.ARRAY_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		arr := args[0].(*object.Array)
+		sep := args[1].(*object.String).Value
+
+		parts := make([]string, 0, len(arr.Elements))
+		for _, el := range arr.Elements {
+			str, ok := el.(*object.String)
+			if !ok {
+				return newError("elements of the first argument to `join` must be STRING, got %s",
+					el.Type())
+			}
+			parts = append(parts, str.Value)
+		}
+
+		return &object.String{Value: strings.Join(parts, sep)}
+	}},
+	"trim":      stringTransform("trim", strings.TrimSpace),
+	"trimLeft":  stringTransform("trimLeft", func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) }),
+	"trimRight": stringTransform("trimRight", func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) }),
+	"upper":     stringTransform("upper", strings.ToUpper),
+	"lower":     stringTransform("lower", strings.ToLower),
+	"replace": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		err := checkArgs("replace", args, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ)
+		if err != nil {
+			return err
+		}
+		str := args[0].(*object.String).Value
+		old := args[1].(*object.String).Value
+		replacement := args[2].(*object.String).Value
+
+		return &object.String{Value: strings.ReplaceAll(str, old, replacement)}
+	}},
+	"contains":   stringPredicate("contains", strings.Contains),
+	"startsWith": stringPredicate("startsWith", strings.HasPrefix),
+	"endsWith":   stringPredicate("endsWith", strings.HasSuffix),
+	"indexOf": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("indexOf", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		str := args[0].(*object.String).Value
+		substr := args[1].(*object.String).Value
+
+		i := strings.Index(str, substr)
+		if i == -1 {
+			return nativeToIntegerObject(-1)
+		}
+		// Convert the byte offset into a character offset.
+		return nativeToIntegerObject(utf8.RuneCountInString(str[:i]))
+	}},
+	"repeat": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("repeat", args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+			return err
+		}
+		str := args[0].(*object.String).Value
+		count := args[1].(*object.Integer).Value
+
+		if count < 0 {
+			return newError("second argument to `repeat` must not be negative, got %d", count)
+		}
+		// Divide instead of multiplying so the check itself can't overflow.
+		if len(str) > 0 && count > maxStringSize/int64(len(str)) {
+			return newError("result of `repeat` is too large, the limit is %d bytes", maxStringSize)
+		}
+
+		return &object.String{Value: strings.Repeat(str, int(count))}
+	}},
+	"chars": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs("chars", args, object.STRING_OBJ); err != nil {
+			return err
+		}
+		str := args[0].(*object.String).Value
+
+		chars := make([]object.Object, 0, len(str))
+		for _, r := range str {
+			chars = append(chars, &object.String{Value: string(r)})
+		}
+
+		return &object.Array{Elements: chars}
+	}},
+	"substr": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		// The length is optional, by default it goes until the end of the string.
+		if len(args) == 2 {
+			if err := checkArgs("substr", args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+		} else {
+			err := checkArgs("substr", args, object.STRING_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ)
+			if err != nil {
+				return err
+			}
+		}
+		runes := []rune(args[0].(*object.String).Value)
+		size := int64(len(runes))
+
+		start := args[1].(*object.Integer).Value
+		if start < 0 {
+			return newError("second argument to `substr` must not be negative, got %d", start)
+		}
+		end := size
+		if len(args) == 3 {
+			length := args[2].(*object.Integer).Value
+			if length < 0 {
+				return newError("third argument to `substr` must not be negative, got %d", length)
+			}
+			end = start + min(length, size-start)
+		}
+
+		// Out of bounds substrings are cut at the end of the string.
+		start = min(start, size)
+		end = min(end, size)
+		return &object.String{Value: string(runes[start:end])}
+	}},
+}
+
+// Creates a builtin that takes a single string and returns a modified one.
+func stringTransform(name string, transform func(string) string) *object.Builtin {
+	return &object.Builtin{Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs(name, args, object.STRING_OBJ); err != nil {
+			return err
+		}
+
+		return &object.String{Value: transform(args[0].(*object.String).Value)}
+	}}
+}
+
+// Creates a builtin that checks a property of two strings.
+func stringPredicate(name string, predicate func(string, string) bool) *object.Builtin {
+	return &object.Builtin{Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if err := checkArgs(name, args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		str := args[0].(*object.String).Value
+		other := args[1].(*object.String).Value
+
+		return nativeToBooleanObject(predicate(str, other))
+	}}
+}
+
+func nativeToStringArray(strs []string) *object.Array {
+	elements := make([]object.Object, 0, len(strs))
+	for _, s := range strs {
+		elements = append(elements, &object.String{Value: s})
+	}
+	return &object.Array{Elements: elements}
+}
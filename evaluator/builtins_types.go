@@ -0,0 +1,132 @@
+package evaluator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ManuelGarciaF/go-interpreter/object"
+)
+
+var typeBuiltins = map[string]*object.Builtin{
+	"type": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		return &object.String{Value: args[0].Type().String()}
+	}},
+	"str": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		return &object.String{Value: displayString(args[0])}
+	}},
+	"int": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Integer:
+			return arg
+		case *object.Float:
+			// Truncates towards zero.
+			if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) ||
+				arg.Value >= math.MaxInt64 || arg.Value < math.MinInt64 {
+				return newError("could not convert %s to INTEGER", arg.Inspect())
+			}
+			return &object.Integer{Value: int64(arg.Value)}
+		case *object.String:
+			value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+			if err != nil {
+				return newError("could not convert %s to INTEGER", arg.Inspect())
+			}
+			return &object.Integer{Value: value}
+		case *object.Boolean:
+			if arg.Value {
+				return &object.Integer{Value: 1}
+			}
+			return &object.Integer{Value: 0}
+		default:
+			return newError("argument to `int` not supported, got %s", arg.Type())
+		}
+	}},
+	"float": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Float:
+			return arg
+		case *object.Integer:
+			return &object.Float{Value: float64(arg.Value)}
+		case *object.String:
+			value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+			if err != nil {
+				return newError("could not convert %s to FLOAT", arg.Inspect())
+			}
+			return &object.Float{Value: value}
+		case *object.Boolean:
+			if arg.Value {
+				return &object.Float{Value: 1}
+			}
+			return &object.Float{Value: 0}
+		default:
+			return newError("argument to `float` not supported, got %s", arg.Type())
+		}
+	}},
+	"bool": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		// Same rules as conditions, only false and null are falsy.
+		return nativeToBooleanObject(isTruthy(args[0]))
+	}},
+	"array": {Fn: func(ctx *object.Context, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Array:
+			elements := make([]object.Object, len(arg.Elements))
+			copy(elements, arg.Elements)
+			return &object.Array{Elements: elements}
+		case *object.String:
+			// One element per character.
+			chars := make([]object.Object, 0, len(arg.Value))
+			for _, r := range arg.Value {
+				chars = append(chars, &object.String{Value: string(r)})
+			}
+			return &object.Array{Elements: chars}
+		case *object.Hash:
+			// One [key, value] array per pair, like `entries`.
+			entries := make([]object.Object, 0, arg.Len())
+			for _, pair := range arg.Pairs() {
+				entries = append(entries, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+			}
+			return &object.Array{Elements: entries}
+		default:
+			return newError("argument to `array` not supported, got %s", arg.Type())
+		}
+	}},
+}
+
+// The representation of an object shown to users, which differs from Inspect in that
+// strings are not quoted. Strings inside arrays and hashes still are, to keep them apart.
+func displayString(obj object.Object) string {
+	if str, ok := obj.(*object.String); ok {
+		return str.Value
+	}
+	return obj.Inspect()
+}
@@ -2,9 +2,12 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/ManuelGarciaF/go-interpreter/ast"
 	"github.com/ManuelGarciaF/go-interpreter/object"
+	"github.com/ManuelGarciaF/go-interpreter/token"
 )
 
 var (
@@ -13,101 +16,158 @@ var (
 	FALSE = &object.Boolean{Value: false}
 )
 
-func Eval(node ast.Node, env *object.Environment) object.Object {
+func Eval(node ast.Node, env *object.Environment, ctx *object.Context) object.Object {
 	switch node := node.(type) {
 	// Statements
 	case *ast.Program:
-		return evalProgram(node.Statements, env)
+		return evalProgram(node.Statements, env, ctx)
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env) // We just eval the expression
+		return Eval(node.Expression, env, ctx) // We just eval the expression
 	case *ast.BlockStatement:
-		return evalBlockStatement(node.Statements, env)
+		return evalBlockStatement(node.Statements, env, ctx)
 	case *ast.ReturnStatement:
-		val := Eval(node.Value, env)
+		val := Eval(node.Value, env, ctx)
 		if isError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
+		val := Eval(node.Value, env, ctx)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		// let f = fn() {} names the function, so it shows up in errors.
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+				fn.Name = node.Name.Value
+			}
+		}
+		set := env.Set
+		if node.Token.Type == token.CONST {
+			set = env.SetConst
+		}
+		if err := set(node.Name.Value, val); err != nil {
+			return newError("%s", err)
+		}
+	case *ast.FunctionStatement:
+		fn := newFunction(node.Function, env)
+		if err := env.Set(fn.Name, fn); err != nil {
+			return newError("%s", err)
+		}
 
 	// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env, ctx)
 	case *ast.ArrayLiteral:
-		elements := evalExpressions(node.Elements, env)
+		elements := evalExpressions(node.Elements, env, ctx)
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
 	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return evalHashLiteral(node, env, ctx)
 	case *ast.Boolean:
 		return nativeToBooleanObject(node.Value)
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return evalIdentifier(node, env, ctx)
 	case *ast.PrefixExpression:
-		right := Eval(node.Right, env)
+		right := Eval(node.Right, env, ctx)
 		if isError(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
-		left := Eval(node.Left, env)
+		left := Eval(node.Left, env, ctx)
 		if isError(left) {
 			return left
 		}
-		right := Eval(node.Right, env)
+		right := Eval(node.Right, env, ctx)
 		if isError(right) {
 			return right
 		}
 		return evalInfixExpression(node.Operator, left, right)
 	case *ast.IfExpression:
-		return evalIfExpression(node, env)
+		return evalIfExpression(node, env, ctx)
 	case *ast.FunctionLiteral:
-		return &object.Function{
-			Parameters: node.Parameters,
-			Body:       node.Body,
-			Env:        env, // The function carries arround a reference to the env where it was created
-		}
+		return newFunction(node, env)
 	case *ast.CallExpression:
-		function := Eval(node.Function, env) // We get the function object
+		function := Eval(node.Function, env, ctx) // We get the function object
 		if isError(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
+		args := evalExpressions(node.Arguments, env, ctx)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
 
-		return applyFunction(function, args)
+		return applyFunction(function, args, ctx)
 	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
+		left := Eval(node.Left, env, ctx)
 		if isError(left) {
 			return left
 		}
-		index := Eval(node.Index, env)
+		index := Eval(node.Index, env, ctx)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env, ctx)
+	case *ast.ConditionalExpression:
+		condition := Eval(node.Condition, env, ctx)
+		if isError(condition) {
+			return condition
+		}
+		if isTruthy(condition) {
+			return Eval(node.Consequence, env, ctx)
+		}
+		return Eval(node.Alternative, env, ctx)
+	case *ast.NullCoalescingExpression:
+		left := Eval(node.Left, env, ctx)
+		if isError(left) || !isNull(left) {
+			return left
+		}
+		return Eval(node.Right, env, ctx)
+	case *ast.OptionalIndexExpression:
+		left := Eval(node.Left, env, ctx)
+		if isError(left) || isNull(left) {
+			return left
+		}
+		index := Eval(node.Index, env, ctx)
 		if isError(index) {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.OptionalCallExpression:
+		function := Eval(node.Function, env, ctx)
+		if isError(function) || isNull(function) {
+			return function
+		}
+		args := evalExpressions(node.Arguments, env, ctx)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+
+		return applyFunction(function, args, ctx)
+	case *ast.PipeExpression:
+		return evalPipeExpression(node, env, ctx)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env, ctx)
 	}
 
 	return nil
 }
 
-func evalProgram(statements []ast.Statement, env *object.Environment) object.Object {
+func evalProgram(statements []ast.Statement, env *object.Environment, ctx *object.Context) object.Object {
 	var result object.Object
 
 	for _, statement := range statements {
 		// We return the value of the last statement
-		result = Eval(statement, env)
+		result = Eval(statement, env, ctx)
 
 		switch result := result.(type) {
 		// If the last statement was a return, finish evaluating the block and return the value
@@ -122,12 +182,12 @@ func evalProgram(statements []ast.Statement, env *object.Environment) object.Obj
 	return result
 }
 
-func evalBlockStatement(statements []ast.Statement, env *object.Environment) object.Object {
+func evalBlockStatement(statements []ast.Statement, env *object.Environment, ctx *object.Context) object.Object {
 	var result object.Object
 
 	for _, statement := range statements {
 		// We return the value of the last statement
-		result = Eval(statement, env)
+		result = Eval(statement, env, ctx)
 
 		// If there was a return or error, we must stop evaluation
 		if result != nil {
@@ -160,6 +220,8 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalTildePrefixOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -170,27 +232,38 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
+}
 
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+func evalTildePrefixOperatorExpression(right object.Object) object.Object {
+	integer, ok := right.(*object.Integer)
+	if !ok {
+		return newError("unknown operator: ~%s", right.Type())
+	}
+	return &object.Integer{Value: ^integer.Value}
 }
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right): // At least one of them is a float
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 
-	// We use pointer comparison since in these cases at least one of the arguments is a boolean
-	// or null
+	// Other types are compared by value, arrays and hashes included.
 	case operator == "==":
-		return nativeToBooleanObject(left == right)
+		return nativeToBooleanObject(object.Equal(left, right))
 	case operator == "!=":
-		return nativeToBooleanObject(left != right)
+		return nativeToBooleanObject(!object.Equal(left, right))
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s",
 			left.Type(), operator, right.Type())
@@ -212,6 +285,11 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
 		return &object.Integer{Value: leftVal / rightVal}
+	case "**":
+		if rightVal < 0 {
+			return newError("negative exponent for INTEGER: %d", rightVal)
+		}
+		return &object.Integer{Value: intPow(leftVal, rightVal)}
 	case "<":
 		return nativeToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -220,56 +298,146 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return nativeToBooleanObject(leftVal == rightVal)
 	case "!=":
 		return nativeToBooleanObject(leftVal != rightVal)
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		if operator == "<<" {
+			return &object.Integer{Value: leftVal << rightVal}
+		}
+		// Arithmetic shift, the sign is kept.
+		return &object.Integer{Value: leftVal >> rightVal}
 	default:
 		return newError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
-func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
-	// Only concatenation is allowed
-	if operator != "+" {
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+// Integers are converted to floats when mixed with them.
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+	case "<":
+		return nativeToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
 	}
+}
 
+// Exponentiation by squaring, overflows wrap around like the other integer operators.
+func intPow(base, exp int64) int64 {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// Only valid for numbers
+func toFloat(obj object.Object) float64 {
+	if i, ok := obj.(*object.Integer); ok {
+		return float64(i.Value)
+	}
+	return obj.(*object.Float).Value
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
-	// Return a new string with the concatenated value
-	return &object.String{Value: leftVal + rightVal}
+
+	// Only concatenation and comparisons are allowed
+	switch operator {
+	case "+":
+		// Return a new string with the concatenated value
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
 }
 
-func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
+// Every part is converted with the same rules as `str`
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment, ctx *object.Context) object.Object {
+	var sb strings.Builder
+
+	for _, part := range node.Parts {
+		evaluated := Eval(part, env, ctx)
+		if isError(evaluated) {
+			return evaluated
+		}
+		sb.WriteString(displayString(evaluated))
+	}
+
+	return &object.String{Value: sb.String()}
+}
+
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment, ctx *object.Context) object.Object {
+	condition := Eval(ie.Condition, env, ctx)
 	if isError(condition) {
 		return condition
 	}
 
 	if isTruthy(condition) {
-		return Eval(ie.Consequence, env)
+		return Eval(ie.Consequence, env, ctx)
 	}
 	if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+		return Eval(ie.Alternative, env, ctx)
 	}
 	return NULL
 }
 
-func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+func evalIdentifier(node *ast.Identifier, env *object.Environment, ctx *object.Context) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
 
-	if builtin, ok := builtins[node.Value]; ok {
+	if builtin, ok := ctx.Builtins.Get(node.Value); ok {
 		return builtin
 	}
 
 	return newError("identifier not found: " + node.Value)
 }
 
-func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+func evalExpressions(exps []ast.Expression, env *object.Environment, ctx *object.Context) []object.Object {
 	results := make([]object.Object, 0, len(exps))
 
 	for _, e := range exps {
-		evaluated := Eval(e, env)
+		evaluated := Eval(e, env, ctx)
 		// We return just the error if there is one
 		if isError(evaluated) {
 			return []object.Object{evaluated}
@@ -280,22 +448,85 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return results
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// ApplyFunction calls fn, which may be a monkey function or a builtin, with args. Builtins
+// defined outside this package can use it to call back into user functions.
+func ApplyFunction(fn object.Object, args []object.Object, ctx *object.Context) object.Object {
+	return applyFunction(fn, args, ctx)
+}
+
+func newFunction(node *ast.FunctionLiteral, env *object.Environment) *object.Function {
+	fn := &object.Function{
+		Parameters: node.Parameters,
+		Body:       node.Body,
+		Env:        env, // The function carries arround a reference to the env where it was created
+	}
+
+	// Named functions can call themselves without depending on the name being bound in
+	// env, so they get an env of their own with just the name.
+	if node.Name != nil {
+		fn.Name = node.Name.Value
+		fn.Env = object.NewEnclosedEnvironment(env)
+		fn.Env.Set(fn.Name, fn)
+	}
+
+	return fn
+}
+
+func applyFunction(fn object.Object, args []object.Object, ctx *object.Context) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		if len(args) != len(fn.Parameters) {
+			if fn.Name != "" {
+				return newError("wrong number of arguments to `%s`. got=%d, want=%d",
+					fn.Name, len(args), len(fn.Parameters))
+			}
+			return newError("wrong number of arguments. got=%d, want=%d",
+				len(args), len(fn.Parameters))
+		}
 		extendedEnv := extendFunctionEnv(fn, args)
 		// We evaluate the body, a block statement, using an enclosed env that contains the arguments
-		evaluated := Eval(fn.Body, extendedEnv)
+		evaluated := Eval(fn.Body, extendedEnv, ctx)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			errObj.Stack = append(errObj.Stack, fn.Name)
+		}
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
 		// No need to unwrap, builtins never return a object.ReturnValue
-		return fn.Fn(args...)
+		return fn.Fn(ctx, args...)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 
 }
 
+// For xs |> f(a, b) calls f(xs, a, b), for xs |> f calls f(xs).
+func evalPipeExpression(node *ast.PipeExpression, env *object.Environment, ctx *object.Context) object.Object {
+	left := Eval(node.Left, env, ctx)
+	if isError(left) {
+		return left
+	}
+
+	call, ok := node.Right.(*ast.CallExpression)
+	if !ok {
+		function := Eval(node.Right, env, ctx)
+		if isError(function) {
+			return function
+		}
+		return applyFunction(function, []object.Object{left}, ctx)
+	}
+
+	function := Eval(call.Function, env, ctx)
+	if isError(function) {
+		return function
+	}
+	args := evalExpressions(call.Arguments, env, ctx)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	return applyFunction(function, append([]object.Object{left}, args...), ctx)
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
 	env := object.NewEnclosedEnvironment(fn.Env)
 	// Set all the args in the enclosed env
@@ -319,6 +550,8 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ: // Array indexing
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ: // String indexing
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ: // Hash indexing
 		return evalHashIndexExpression(left, index)
 	default:
@@ -328,71 +561,281 @@ func evalIndexExpression(left, index object.Object) object.Object {
 
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
-	i := index.(*object.Integer).Value
 	size := int64(len(arrayObject.Elements))
 
-	if i < 0 || i >= size {
+	i, ok := normalizeIndex(index.(*object.Integer).Value, size)
+	if !ok {
 		return NULL
 	}
 
 	return arrayObject.Elements[i]
 }
 
+// Strings are indexed by character, not by byte.
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	size := int64(len(runes))
+
+	i, ok := normalizeIndex(index.(*object.Integer).Value, size)
+	if !ok {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[i])}
+}
+
+// Negative indices count from the end, so -1 is the last element. ok is false if the
+// index is out of range.
+func normalizeIndex(i, size int64) (int64, bool) {
+	if i < 0 {
+		i += size
+	}
+	return i, i >= 0 && i < size
+}
+
+// Assignments evaluate to the assigned value.
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment, ctx *object.Context) object.Object {
+	value := Eval(node.Value, env, ctx)
+	if isError(value) {
+		return value
+	}
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		if err := env.Assign(target.Value, value); err != nil {
+			return newError("%s", err)
+		}
+		return value
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env, ctx)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env, ctx)
+		if isError(index) {
+			return index
+		}
+		return evalIndexAssignment(left, index, value)
+	default:
+		return newError("cannot assign to %s", node.Target)
+	}
+}
+
+func evalIndexAssignment(left, index, value object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		if left.Frozen {
+			return newError("cannot modify frozen ARRAY")
+		}
+		integer, ok := index.(*object.Integer)
+		if !ok {
+			return newError("index operator not supported: ARRAY[%s]", index.Type())
+		}
+		i, ok := normalizeIndex(integer.Value, int64(len(left.Elements)))
+		if !ok {
+			return newError("index out of range: %d", integer.Value)
+		}
+		if contains(value, left, make(map[object.Object]bool)) {
+			return newError("cannot put an ARRAY inside itself")
+		}
+		left.Elements[i] = value
+	case *object.Hash:
+		if left.Frozen {
+			return newError("cannot modify frozen HASH")
+		}
+		key, ok := object.AsHashable(index)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		if contains(value, left, make(map[object.Object]bool)) {
+			return newError("cannot put a HASH inside itself")
+		}
+		left.Set(key, value)
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+
+	return value
+}
+
+// Reports whether target is obj or is found inside of it. Assignments that would create
+// cycles are rejected, since printing, comparing and hashing don't expect them.
+func contains(obj, target object.Object, visited map[object.Object]bool) bool {
+	if obj == target {
+		return true
+	}
+	if visited[obj] {
+		return false
+	}
+
+	switch obj := obj.(type) {
+	case *object.Array:
+		visited[obj] = true
+		for _, el := range obj.Elements {
+			if contains(el, target, visited) {
+				return true
+			}
+		}
+	case *object.Hash:
+		visited[obj] = true
+		for _, pair := range obj.Pairs() {
+			if contains(pair.Value, target, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment, ctx *object.Context) object.Object {
+	left := Eval(node.Left, env, ctx)
+	if isError(left) {
+		return left
+	}
+
+	// Omitted parts are left as nil
+	parts := make([]*int64, 0, 3)
+	for _, exp := range []ast.Expression{node.Start, node.End, node.Step} {
+		if exp == nil {
+			parts = append(parts, nil)
+			continue
+		}
+		part := Eval(exp, env, ctx)
+		if isError(part) {
+			return part
+		}
+		integer, ok := part.(*object.Integer)
+		if !ok {
+			return newError("slice indices must be INTEGER, got %s", part.Type())
+		}
+		parts = append(parts, &integer.Value)
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		indices, err := sliceIndices(int64(len(left.Elements)), parts[0], parts[1], parts[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, 0, len(indices))
+		for _, i := range indices {
+			elements = append(elements, left.Elements[i])
+		}
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		indices, err := sliceIndices(int64(len(runes)), parts[0], parts[1], parts[2])
+		if err != nil {
+			return err
+		}
+		sliced := make([]rune, 0, len(indices))
+		for _, i := range indices {
+			sliced = append(sliced, runes[i])
+		}
+		return &object.String{Value: string(sliced)}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// Returns the indices selected by a slice of a sequence of the given size, following the
+// same rules as python. Out of range bounds are clamped instead of being errors.
+func sliceIndices(size int64, start, end, step *int64) ([]int64, *object.Error) {
+	var s, e, st int64 = 0, size, 1
+	if step != nil {
+		st = *step
+	}
+	if st == 0 {
+		return nil, newError("slice step cannot be zero")
+	}
+
+	// With a negative step, the slice goes backwards from the last element. -1 as the end
+	// means going past the first element.
+	lower, upper := int64(0), size
+	if st < 0 {
+		s, e = size-1, -1
+		lower, upper = -1, size-1
+	}
+
+	clamp := func(i int64) int64 {
+		if i < 0 {
+			i += size
+		}
+		return max(lower, min(i, upper))
+	}
+	if start != nil {
+		s = clamp(*start)
+	}
+	if end != nil {
+		e = clamp(*end)
+	}
+
+	// Count the indices up front, adding a large step could overflow. s and e are already
+	// clamped, so only the step needs uint64 arithmetic.
+	var count uint64
+	if st > 0 && s < e {
+		count = uint64(e-s-1)/uint64(st) + 1
+	} else if st < 0 && s > e {
+		count = uint64(s-e-1)/uint64(-st) + 1
+	}
+
+	indices := make([]int64, 0, count)
+	for i := uint64(0); i < count; i++ {
+		indices = append(indices, s+int64(i)*st)
+	}
+	return indices, nil
+}
+
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
+	key, ok := object.AsHashable(index)
 	if !ok {
 		return newError("unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	value, ok := hashObject.Get(key)
 	if !ok {
 		return NULL
 	}
 
-	return pair.Value
+	return value
 }
 
-func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair, len(node.Pairs))
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment, ctx *object.Context) object.Object {
+	hash := object.NewHash(len(node.Pairs))
 
-	for keyNode, valueNode := range node.Pairs {
-		key := Eval(keyNode, env)
+	// Pairs are evaluated in source order.
+	for _, pairNode := range node.Pairs {
+		key := Eval(pairNode.Key, env, ctx)
 		if isError(key) {
 			return key
 		}
 		// The key must be a hashable object
-		hashKey, ok := key.(object.Hashable)
+		hashKey, ok := object.AsHashable(key)
 		if !ok {
 			return newError("unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env)
+		value := Eval(pairNode.Value, env, ctx)
 		if isError(value) {
 			return value
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{
-			Key:   key,
-			Value: value,
-		}
+		hash.Set(hashKey, value)
 	}
 
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func isTruthy(obj object.Object) bool {
 	switch obj {
-	case NULL:
-		return false
 	case TRUE:
 		return true
 	case FALSE:
 		return false
 	default:
-		return true
+		return !isNull(obj)
 	}
 }
 
@@ -403,3 +846,9 @@ func newError(format string, a ...any) *object.Error {
 func isError(o object.Object) bool {
 	return o != nil && o.Type() == object.ERROR_OBJ
 }
+
+// Checks the type instead of comparing with NULL, so nulls created by the host also count.
+// Statements without a value, like let, evaluate to nil, which is also treated as null.
+func isNull(o object.Object) bool {
+	return o == nil || o.Type() == object.NULL_OBJ
+}
@@ -1,6 +1,8 @@
 package evaluator
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/ManuelGarciaF/go-interpreter/lexer"
@@ -28,6 +30,17 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"0xFF + 0o10 + 0b11", 266},
+		{"1_000 * 2", 2000},
+		{"0b1100 & 0b1010", 8},
+		{"0b1100 | 0b1010", 14},
+		{"0b1100 ^ 0b1010", 6},
+		{"~0", -1},
+		{"~5 + 1", -5},
+		{"1 << 10", 1024},
+		{"-16 >> 2", -4},
+		{"0xFF & 0x0F << 4", 240},
+		{"1 | 2 ^ 3 & 4", 3},
 	}
 
 	for _, tt := range tests {
@@ -36,6 +49,78 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestPowerOperator(t *testing.T) {
+	tests := []inspectTest{
+		{"2 ** 10", "1024"},
+		{"2 ** 3 ** 2", "512"},
+		{"(2 ** 3) ** 2", "64"},
+		{"-2 ** 2", "-4"},
+		{"(-2) ** 3", "-8"},
+		{"2 * 3 ** 2", "18"},
+		{"5 ** 0", "1"},
+		{`float("2") ** -1`, "0.5"},
+		{`4 ** float("0.5")`, "2.0"},
+		{"2 ** -1", "ERROR: negative exponent for INTEGER: -1"},
+		{`"a" ** 2`, "ERROR: type mismatch: STRING ** INTEGER"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestConditionalAndNullOperators(t *testing.T) {
+	tests := []inspectTest{
+		{"1 < 2 ? 10 : 20", "10"},
+		{"1 > 2 ? 10 : 20", "20"},
+		{`0 ? "zero is truthy" : "no"`, `"zero is truthy"`},
+		{"let x = 5; x > 3 ? x > 4 ? 1 : 2 : 3", "1"},
+		{"let n = 7; n < 0 ? -1 : n == 0 ? 0 : 1", "1"},
+		{"true ? 1 : missing", "1"},
+		{"false ? missing : 2", "2"},
+		{"missing ? 1 : 2", "ERROR: identifier not found: missing"},
+		{`{"a": 1}["b"] ?? 5`, "5"},
+		{`{"a": 1}["a"] ?? missing`, "1"},
+		{"false ?? 1", "false"},
+		{"if (false) { 1 } ?? 2 ?? 3", "2"},
+		{"let h = {\"a\": {\"b\": 1}}; h?.[\"a\"]?.[\"b\"]", "1"},
+		{"let h = {\"a\": 1}; h[\"x\"]?.[\"y\"]?.[\"z\"]", "null"},
+		{"let h = {}; h[\"x\"]?.[missing]", "null"},
+		{"let h = {}; h[\"x\"]?.[0] ?? \"default\"", `"default"`},
+		{"[1, 2]?.[-1]", "2"},
+		{"let f = fn(x) { x * 2 }; f?.(21)", "42"},
+		{"let h = {}; h[\"f\"]?.(missing)", "null"},
+		{"let h = {\"f\": len}; h[\"f\"]?.([1, 2, 3])", "3"},
+		{"1?.(2)", "ERROR: not a function: INTEGER"},
+		{"let f = fn() { let x = 1 }; f() ?? 2", "2"},
+		{"let f = fn() { let x = 1 }; f()?.[0] ?? 3", "3"},
+		{"let f = fn() { let x = 1 }; f()?.(1) ?? 4", "4"},
+		{"let f = fn() { let x = 1 }; f() ? 1 : 2", "2"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestPipeExpressions(t *testing.T) {
+	tests := []inspectTest{
+		{"[1, 2, 3] |> len", "3"},
+		{"[1, 2, 3] |> push(4)", "[1, 2, 3, 4]"},
+		{
+			"[1, 2, 3, 4] |> map(fn(x) { x * 10 }) |> filter(fn(x) { x > 15 }) |> reduce(fn(acc, x) { acc + x }, 0)",
+			"90",
+		},
+		{"let add = fn(a, b) { a + b }; 1 + 1 |> add(3)", "5"},
+		{"let double = fn(x) { x * 2 }; 5 |> double |> double", "20"},
+		{"5 |> fn(x) { x - 1 }", "4"},
+		{"[] |> len ? 1 : 2", "1"},
+		{"1 |> 2", "ERROR: not a function: INTEGER"},
+		{"1 |> len", "ERROR: argument to `len` not supported, got INTEGER"},
+		{"missing |> len", "ERROR: identifier not found: missing"},
+		{"[1] |> push(missing)", "ERROR: identifier not found: missing"},
+		{"let f = fn(a) { a }; 1 |> f(2)", "ERROR: wrong number of arguments to `f`. got=2, want=1"},
+	}
+
+	testInspect(t, tests)
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 
@@ -50,6 +135,44 @@ func TestStringLiteral(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []inspectTest{
+		{`let name = "Monkey"; "hello ${name}!"`, `"hello Monkey!"`},
+		{`let items = [1, "a"]; "${len(items)} items: ${items}"`, `"2 items: [1, "a"]"`},
+		{`"${1 + 1}${true}${if (false) { 1 }}"`, `"2truenull"`},
+		{`"outer ${"inner ${1}"}"`, `"outer inner 1"`},
+		{`"${ {"k": 5}["k"] }"`, `"5"`},
+		{`"$ {not} $"`, `"$ {not} $"`},
+		{`"${missing}"`, "ERROR: identifier not found: missing"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestRawAndMultilineStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"`C:\\dir\\${x}`", "C:\\dir\\${x}"},
+		{"let q = \"\"\"\n    SELECT *\n    FROM users\n    \"\"\"; q", "SELECT *\nFROM users"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+		}
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. expected=%q, got=%q", tt.expected, str.Value)
+		}
+	}
+
+	// Raw strings keep their line breaks.
+	testIntegerObject(t, testEval("len(`a\nb`)"), 3)
+}
+
 func TestStringConcatenation(t *testing.T) {
 	input := `"Hello" + " " + "World!"`
 
@@ -126,6 +249,10 @@ func TestArrayIndexExpressions(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
 	}
@@ -141,6 +268,45 @@ func TestArrayIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestSliceAndNegativeIndexExpressions(t *testing.T) {
+	tests := []inspectTest{
+		{"[1, 2, 3][-1]", "3"},
+		{"[1, 2, 3][-3]", "1"},
+		{"[1, 2, 3][-4]", "null"},
+		{`"monkey"[0]`, `"m"`},
+		{`"monkey"[-1]`, `"y"`},
+		{`"ñandú"[4]`, `"ú"`},
+		{`let s = "ñandú"; s[len(s) - 1]`, `"ú"`},
+		{`let s = "ñandú"; s[:len(s)]`, `"ñandú"`},
+		{`"monkey"[6]`, "null"},
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][::2]", "[1, 3]"},
+		{"[1, 2, 3, 4][::-1]", "[4, 3, 2, 1]"},
+		{"[1, 2, 3, 4][-2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:-1]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4][3:1:-1]", "[4, 3]"},
+		{"[1, 2, 3, 4][1:100]", "[2, 3, 4]"},
+		{"[1, 2, 3, 4][3:1]", "[]"},
+		{"[1, 2, 3][2::9223372036854775807]", "[3]"},
+		{`"abc"[1::9223372036854775807]`, `"b"`},
+		{"[1, 2, 3][::-9223372036854775807]", "[3]"},
+		{"[1, 2, 3][::-9223372036854775807 - 1]", "[3]"},
+		{"[1, 2, 3, 4][-100:2]", "[1, 2]"},
+		{"[][::-1]", "[]"},
+		{`"monkey"[1:4]`, `"onk"`},
+		{`"ñandú"[::-1]`, `"údnañ"`},
+		{`"monkey"[::2]`, `"mne"`},
+		{"[1, 2][::0]", "ERROR: slice step cannot be zero"},
+		{`[1, 2]["a":]`, "ERROR: slice indices must be INTEGER, got STRING"},
+		{`{"a": 1}[0:1]`, "ERROR: slice operator not supported: HASH"},
+	}
+
+	testInspect(t, tests)
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `let two = "two";
     {
@@ -158,23 +324,37 @@ func TestHashLiterals(t *testing.T) {
 		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
 	}
 
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "one"}).HashKey():   1,
-		(&object.String{Value: "two"}).HashKey():   2,
-		(&object.String{Value: "three"}).HashKey(): 3,
-		(&object.Integer{Value: 4}).HashKey():      4,
-		TRUE.HashKey():                             5,
-		FALSE.HashKey():                            6,
+	// In source order.
+	expected := []struct {
+		key   object.Hashable
+		value int64
+	}{
+		{&object.String{Value: "one"}, 1},
+		{&object.String{Value: "two"}, 2},
+		{&object.String{Value: "three"}, 3},
+		{&object.Integer{Value: 4}, 4},
+		{TRUE, 5},
+		{FALSE, 6},
 	}
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
 	}
-	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+	for i, tt := range expected {
+		value, ok := result.Get(tt.key)
 		if !ok {
 			t.Errorf("no pair for given key in Pairs")
+			continue
+		}
+		testIntegerObject(t, value, tt.value)
+
+		if key := result.Pairs()[i].Key; key.Inspect() != tt.key.Inspect() {
+			t.Errorf("pair %d has wrong key. expected=%s, got=%s", i, tt.key.Inspect(), key.Inspect())
 		}
-		testIntegerObject(t, pair.Value, expectedValue)
+	}
+
+	expectedInspect := `{"one": 1, "two": 2, "three": 3, 4: 4, true: 5, false: 6}`
+	if result.Inspect() != expectedInspect {
+		t.Errorf("wrong Inspect output. expected=%q, got=%q", expectedInspect, result.Inspect())
 	}
 }
 
@@ -190,6 +370,11 @@ func TestHashIndexExpressions(t *testing.T) {
 		{`{5: 5}[5]`, 5},
 		{`{true: 5}[true]`, 5},
 		{`{false: 5}[false]`, 5},
+		{`{[1, 2]: 5}[[1, 2]]`, 5},
+		{`{[1, 2]: 5}[[2, 1]]`, nil},
+		{`let x = 1; let y = "a"; {[x, y]: 5}[[1, "a"]]`, 5},
+		{`{{"a": 1, "b": 2}: 5}[{"b": 2, "a": 1}]`, 5},
+		{`{[[1], {"a": []}]: 5}[[[1], {"a": []}]]`, 5},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -226,6 +411,24 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{`"a" == "a"`, true},
+		{`"a" != "a"`, false},
+		{`"a" == "b"`, false},
+		{`[1, [2, "3"]] == [1, [2, "3"]]`, true},
+		{`[1, 2] == [1, 2, 3]`, false},
+		{`[1, 2] != [2, 1]`, true},
+		{`[] == []`, true},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`if (false) { 1 } == if (false) { 2 }`, true},
+		{`[1] == {1: 1}`, false},
+		{`[1] == [float(1)]`, true},
+		{`{"a": float("2.0")} == {"a": 2}`, true},
+		{`[1] == [float("1.5")]`, false},
+		{`1 == "1"`, false},
+		{`let f = fn(x) { x }; f == f`, true},
+		{`fn(x) { x } == fn(x) { x }`, false},
 	}
 
 	for _, tt := range tests {
@@ -317,6 +520,10 @@ func TestErrorHandling(t *testing.T) {
 		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
 		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
 		{`"Hello" - "World"`, "unknown operator: STRING - STRING"},
+		{"1 << -1", "negative shift count: -1"},
+		{"8 >> -2", "negative shift count: -2"},
+		{"~true", "unknown operator: ~BOOLEAN"},
+		{"1 & 1 == 1", "type mismatch: INTEGER & BOOLEAN"},
 		{`
 if (10 > 1) {
   if (10 > 1) {
@@ -328,6 +535,8 @@ if (10 > 1) {
 `, "unknown operator: BOOLEAN + BOOLEAN"},
 		{"foobar", "identifier not found: foobar"},
 		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{`{[1, fn(x) { x }]: 1}`, "unusable as hash key: ARRAY"},
+		{`{{"f": fn(x) { x }}: 1}`, "unusable as hash key: HASH"},
 	}
 
 	for _, tt := range tests {
@@ -406,6 +615,127 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestArrowFunctions(t *testing.T) {
+	tests := []inspectTest{
+		{"let double = x => x * 2; double(21)", "42"},
+		{"let add = (a, b) => { let sum = a + b; sum }; add(1, 2)", "3"},
+		{"let answer = () => 42; answer()", "42"},
+		{"map([1, 2, 3], x => x * x)", "[1, 4, 9]"},
+		{"[1, 2, 3, 4] |> filter(x => x > 2)", "[3, 4]"},
+		{"reduce([1, 2, 3], (acc, x) => acc + x, 0)", "6"},
+		{"let adder = x => y => x + y; adder(2)(3)", "5"},
+		{"let f = x => { return x; 0 }; f(7)", "7"},
+		{"(x => x)(1, 2)", "ERROR: wrong number of arguments. got=2, want=1"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestNamedFunctions(t *testing.T) {
+	tests := []inspectTest{
+		{"fn fact(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(5)", "120"},
+		{"let f = fn fib(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; f(10)", "55"},
+		{"map([3, 4], fn sum(n) { if (n == 0) { 0 } else { n + sum(n - 1) } })", "[6, 10]"},
+		// The name of a function expression is only visible inside of it.
+		{"let f = fn inner() { 1 }; inner", "ERROR: identifier not found: inner"},
+		// Rebinding the outer name doesn't break the recursion.
+		{"fn count(n) { if (n == 0) { 0 } else { 1 + count(n - 1) } }; let g = count; let count = 5; g(3)", "3"},
+		{"fn add(a, b) { a + b } add(1, 2)", "3"},
+		{"fn twice(x) { x * 2 }; twice", "fn twice(x) {\n{(x * 2)}\n}"},
+		{"let double = x => x * 2; double", "fn double(x) {\n{(x * 2)}\n}"},
+		{"let make = fn() { fn(x) { x } }; let id = make(); id", "fn(x) {\n{x}\n}"},
+		{"fn named(a) { a }; let other = named; other", "fn named(a) {\n{a}\n}"},
+		{"fn f(a) { a }; f()", "ERROR: wrong number of arguments to `f`. got=0, want=1"},
+		{"fn(a) { a }()", "ERROR: wrong number of arguments. got=0, want=1"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestErrorStack(t *testing.T) {
+	input := `
+fn inner(x) { x + true }
+let middle = fn(x) { map([x], fn(y) { inner(y) }) };
+fn outer() { middle(1) }
+outer()`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := []string{"inner", "", "middle", "outer"}
+	if strings.Join(errObj.Stack, ",") != strings.Join(expected, ",") {
+		t.Errorf("wrong stack. expected=%q, got=%q", expected, errObj.Stack)
+	}
+
+	expectedTrace := "\tin inner\n\tin <anonymous>\n\tin middle\n\tin outer"
+	if errObj.StackTrace() != expectedTrace {
+		t.Errorf("wrong trace. expected=%q, got=%q", expectedTrace, errObj.StackTrace())
+	}
+
+	if trace := testEval("1 + true").(*object.Error).StackTrace(); trace != "" {
+		t.Errorf("expected no trace outside of functions. got=%q", trace)
+	}
+}
+
+func TestConstAndAssignment(t *testing.T) {
+	tests := []inspectTest{
+		{"const x = 5; x * 2", "10"},
+		{"let x = 1; x = x + 1; x", "2"},
+		{"let a = 1; let b = 2; a = b = 3; a + b", "6"},
+		{"let x = 1; let f = fn() { x = 10 }; f(); x", "10"},
+		{"let x = 1; let f = fn() { let x = 2; x = 3; x }; f() + x", "4"},
+		{"let count = 0; each([1, 2, 3], fn(n) { count = count + n }); count", "6"},
+		{"const x = 5; let x = 6", "ERROR: cannot assign to constant: x"},
+		{"const x = 5; const x = 6", "ERROR: cannot assign to constant: x"},
+		{"const x = 5; x = 6", "ERROR: cannot assign to constant: x"},
+		{"const x = 5; let f = fn() { x = 6 }; f()", "ERROR: cannot assign to constant: x"},
+		{"const f = 1; fn f() { 2 }", "ERROR: cannot assign to constant: f"},
+		// Inner scopes can shadow constants.
+		{"const x = 5; let f = fn(x) { x * 2 }; f(1)", "2"},
+		{"const x = 5; let f = fn() { let x = 1; x }; f() + x", "6"},
+		{"let x = 5; const x = 6; x", "6"},
+		{"y = 1", "ERROR: identifier not found: y"},
+		{"const xs = [1, 2]; xs[0] = 5; xs", "[5, 2]"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestIndexAssignmentAndFreeze(t *testing.T) {
+	tests := []inspectTest{
+		{"let xs = [1, 2, 3]; xs[1] = 20; xs", "[1, 20, 3]"},
+		{"let xs = [1, 2, 3]; xs[-1] = 30; xs", "[1, 2, 30]"},
+		{"let xs = [1]; let ys = xs; ys[0] = 2; xs", "[2]"},
+		{"let xs = [1]; xs[0] = 5", "5"},
+		{"let xs = [1]; xs[1] = 2", "ERROR: index out of range: 1"},
+		{`let xs = [1]; xs["a"] = 2`, "ERROR: index operator not supported: ARRAY[STRING]"},
+		{`let h = {"a": 1}; h["a"] = 2; h["b"] = 3; h`, `{"a": 2, "b": 3}`},
+		{`let h = {}; h[fn() {}] = 1`, "ERROR: unusable as hash key: FUNCTION"},
+		{`let s = "abc"; s[0] = "x"`, "ERROR: index assignment not supported: STRING"},
+		{"let xs = [1]; xs[0] = xs", "ERROR: cannot put an ARRAY inside itself"},
+		{"let xs = [1]; xs[0] = [[xs]]", "ERROR: cannot put an ARRAY inside itself"},
+		{`let h = {}; h["self"] = [h]`, "ERROR: cannot put a HASH inside itself"},
+		{"let xs = freeze([1, 2]); xs[0] = 5", "ERROR: cannot modify frozen ARRAY"},
+		{`let h = freeze({"a": [1]}); h["a"] = 5`, "ERROR: cannot modify frozen HASH"},
+		{`let h = freeze({"a": [1]}); h["a"][0] = 5`, "ERROR: cannot modify frozen ARRAY"},
+		{`let inner = {"b": 1}; freeze([inner]); inner["b"] = 2`, "ERROR: cannot modify frozen HASH"},
+		{"let xs = [1]; let ys = freeze(xs); ys == xs", "true"},
+		{"let xs = freeze([1]); push(xs, 2)", "[1, 2]"},
+		{"let ys = push(freeze([1]), 2); ys[0] = 3; ys", "[3, 2]"},
+		{"freeze(5)", "5"},
+		{"freeze()", "ERROR: wrong number of arguments. got=0, want=1"},
+		// Keys are copied, so changing the original doesn't affect the hash.
+		{"let k = [1]; let h = {k: 1}; k[0] = 2; h[[1]]", "1"},
+		{"let k = [1]; let h = {k: 1}; k[0] = 2; keys(h)[0][0] = 3", "ERROR: cannot modify frozen ARRAY"},
+		{"let k = [1]; let h = {}; h[k] = 1; k[0] = 2; h", "{[1]: 1}"},
+	}
+
+	testInspect(t, tests)
+}
+
 func TestClosures(t *testing.T) {
 	input := `
 let newAdder = fn(x) {
@@ -426,6 +756,7 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("ñandú")`, 5},
 		{`len([])`, 0},
 		{`len([1])`, 1},
 		{`len([1, 2, "banana"])`, 3},
@@ -464,6 +795,338 @@ func TestBuiltinFunctions(t *testing.T) {
 	}
 }
 
+func TestStringBuiltins(t *testing.T) {
+	tests := []inspectTest{
+		{`split("a,b,,c", ",")`, `["a", "b", "", "c"]`},
+		{`split("abc", "")`, `["a", "b", "c"]`},
+		{`join(["a", "b", "c"], "-")`, `"a-b-c"`},
+		{`join([], "-")`, `""`},
+		{`join(["a", 1], "-")`, "ERROR: elements of the first argument to `join` must be STRING, got INTEGER"},
+		{`trim("  hi  ")`, `"hi"`},
+		{`trimLeft("  hi  ")`, `"hi  "`},
+		{`trimRight("  hi  ")`, `"  hi"`},
+		{`trimLeft("  hi")`, `"hi"`},
+		{`trimRight("hi  ")`, `"hi"`},
+		{`upper("Monkey")`, `"MONKEY"`},
+		{`lower("Monkey")`, `"monkey"`},
+		{`replace("a-b-c", "-", "+")`, `"a+b+c"`},
+		{`contains("monkey", "key")`, "true"},
+		{`contains("monkey", "dog")`, "false"},
+		{`startsWith("monkey", "mon")`, "true"},
+		{`endsWith("monkey", "mon")`, "false"},
+		{`indexOf("monkey", "key")`, "3"},
+		{`indexOf("ñandú", "d")`, "3"},
+		{`indexOf("monkey", "dog")`, "-1"},
+		{`repeat("ab", 3)`, `"ababab"`},
+		{`repeat("ab", 9223372036854775807)`, "ERROR: result of `repeat` is too large, the limit is 1073741824 bytes"},
+		{`repeat("", 9223372036854775807)`, `""`},
+		{`repeat("ab", -1)`, "ERROR: second argument to `repeat` must not be negative, got -1"},
+		{`chars("añb")`, `["a", "ñ", "b"]`},
+		{`substr("monkey", 3)`, `"key"`},
+		{`substr("monkey", 1, 3)`, `"onk"`},
+		{`substr("ñandú", 3, 10)`, `"dú"`},
+		{`substr("monkey", 10)`, `""`},
+		{`substr("monkey", 10, 9223372036854775807)`, `""`},
+		{`substr("monkey", 2, 9223372036854775807)`, `"nkey"`},
+		{`substr("monkey", -1)`, "ERROR: second argument to `substr` must not be negative, got -1"},
+		{`upper(1)`, "ERROR: argument to `upper` must be STRING, got INTEGER"},
+		{`split("a", 1)`, "ERROR: second argument to `split` must be STRING, got INTEGER"},
+		{`contains("a")`, "ERROR: wrong number of arguments. got=1, want=2"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestArrayBuiltins(t *testing.T) {
+	tests := []inspectTest{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, "[2, 4, 6]"},
+		{`map(["a", "bc"], len)`, "[1, 2]"},
+		{`map([], fn(x) { x })`, "[]"},
+		{`filter([1, 2, 3, 4], fn(x) { x > 2 })`, "[3, 4]"},
+		{`reduce([1, 2, 3, 4], fn(acc, x) { acc + x }, 0)`, "10"},
+		{`reduce([], fn(acc, x) { acc + x }, 42)`, "42"},
+		{`each([1, 2], fn(x) { x })`, "null"},
+		{`find([1, 2, 3, 4], fn(x) { x > 2 })`, "3"},
+		{`find([1, 2], fn(x) { x > 2 })`, "null"},
+		{`any([1, 2, 3], fn(x) { x > 2 })`, "true"},
+		{`any([], fn(x) { true })`, "false"},
+		{`all([1, 2, 3], fn(x) { x > 2 })`, "false"},
+		{`all([], fn(x) { false })`, "true"},
+		{`sort([3, 1, 2])`, "[1, 2, 3]"},
+		{`sort(["b", "c", "a"])`, `["a", "b", "c"]`},
+		{`sort([3, 1, 2], fn(a, b) { a > b })`, "[3, 2, 1]"},
+		{`sort([1, "a"])`, "ERROR: `sort` can't compare STRING and INTEGER without a comparator"},
+		{`let xs = [3, 1, 2]; sort(xs); xs`, "[3, 1, 2]"},
+		{`reverse([1, 2, 3])`, "[3, 2, 1]"},
+		{`zip([1, 2, 3], ["a", "b"])`, `[[1, "a"], [2, "b"]]`},
+		{`flatten([[1, 2], 3, [[4]]])`, "[1, 2, 3, [4]]"},
+		{`uniq([1, 2, 1, 3, 2])`, "[1, 2, 3]"},
+		{`uniq([fn(x) { x }])`, "ERROR: elements of the argument to `uniq` must be hashable, got FUNCTION"},
+		{`range(4)`, "[0, 1, 2, 3]"},
+		{`range(2, 5)`, "[2, 3, 4]"},
+		{`range(0, 10, 3)`, "[0, 3, 6, 9]"},
+		{`range(5, 0, -2)`, "[5, 3, 1]"},
+		{`range(9223372036854775800, 9223372036854775807, 10)`, "[9223372036854775800]"},
+		{`range(0, 4611686018427387904)`, "ERROR: result of `range` is too large, the limit is 16777216 elements"},
+		{`range(0, 9223372036854775807, -1)`, "[]"},
+		{`range(-9223372036854775807, -9223372036854775800, 5)`, "[-9223372036854775807, -9223372036854775802]"},
+		{`range(-9223372036854775800, -9223372036854775807, -10)`, "[-9223372036854775800]"},
+		{`range(0, 5, 0)`, "ERROR: third argument to `range` must not be 0"},
+		{`map([1, 2], fn(x) { x + true })`, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{`map([1, 2], fn(a, b) { a })`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`map([1, 2], 3)`, "ERROR: second argument to `map` must be FUNCTION, got INTEGER"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []inspectTest{
+		{`keys({"b": 1, "a": 2, 3: 3, true: 4, 1: 5, false: 6})`, `["b", "a", 3, true, 1, false]`},
+		{`values({"b": 1, "a": 2, 3: 3})`, "[1, 2, 3]"},
+		{`entries({"b": 1, "a": 2})`, `[["b", 1], ["a", 2]]`},
+		{`keys({})`, "[]"},
+		{`has({"a": 1}, "a")`, "true"},
+		{`has({"a": 1}, "b")`, "false"},
+		{`has({[1]: 1}, [1])`, "true"},
+		{`has({"a": 1}, [fn(x) { x }])`, "ERROR: unusable as hash key: ARRAY"},
+		{`keys(delete({"a": 1, "b": 2, "c": 3}, "b"))`, `["a", "c"]`},
+		{`let h = {"a": 1}; delete(h, "a"); h["a"]`, "1"},
+		{`delete({"a": 1}, "b")["a"]`, "1"},
+		{`entries(merge({"b": 1, "a": 2}, {"c": 3, "b": 4}))`, `[["b", 4], ["a", 2], ["c", 3]]`},
+		{`len({"a": 1, "b": 2})`, "2"},
+		{`keys([1])`, "ERROR: argument to `keys` must be HASH, got ARRAY"},
+		{`merge({}, 1)`, "ERROR: second argument to `merge` must be HASH, got INTEGER"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestTypeBuiltins(t *testing.T) {
+	tests := []inspectTest{
+		{`type(1)`, `"INTEGER"`},
+		{`type(float(1))`, `"FLOAT"`},
+		{`type("a")`, `"STRING"`},
+		{`type([])`, `"ARRAY"`},
+		{`type({})`, `"HASH"`},
+		{`type(true)`, `"BOOLEAN"`},
+		{`type(if (false) { 1 })`, `"NULL"`},
+		{`type(fn(x) { x })`, `"FUNCTION"`},
+		{`type(len)`, `"BUILTIN"`},
+		{`str("a")`, `"a"`},
+		{`str(42)`, `"42"`},
+		{`str(true)`, `"true"`},
+		{`str(["a", 1])`, `"["a", 1]"`},
+		{`str(float(2))`, `"2.0"`},
+		{`int("42")`, "42"},
+		{`int(" -7 ")`, "-7"},
+		{`int(float("2.9"))`, "2"},
+		{`int(float("-2.9"))`, "-2"},
+		{`int(true)`, "1"},
+		{`int("abc")`, `ERROR: could not convert "abc" to INTEGER`},
+		{`int("99999999999999999999")`, `ERROR: could not convert "99999999999999999999" to INTEGER`},
+		{`int([])`, "ERROR: argument to `int` not supported, got ARRAY"},
+		{`float("2.5")`, "2.5"},
+		{`float(3)`, "3.0"},
+		{`float("1e3")`, "1000.0"},
+		{`float("x")`, `ERROR: could not convert "x" to FLOAT`},
+		{`bool(0)`, "true"},
+		{`bool(if (false) { 1 })`, "false"},
+		{`bool(false)`, "false"},
+		{`array("añ")`, `["a", "ñ"]`},
+		{`array({"a": 1, "b": 2})`, `[["a", 1], ["b", 2]]`},
+		{`array([1, 2])`, "[1, 2]"},
+		{`array(1)`, "ERROR: argument to `array` not supported, got INTEGER"},
+		{`float("1.5") + 1`, "2.5"},
+		{`1 / float("2")`, "0.5"},
+		{`-float("1.5") < 0`, "true"},
+		{`float("2") == 2`, "true"},
+		{`type()`, "ERROR: wrong number of arguments. got=0, want=1"},
+	}
+
+	testInspect(t, tests)
+}
+
+func TestFormatBuiltins(t *testing.T) {
+	tests := []inspectTest{
+		{`format("plain")`, `"plain"`},
+		{`format("%d + %d", 1, 2)`, `"1 + 2"`},
+		{`format("[%5d|%-5d|%05d]", 42, 42, 42)`, `"[   42|42   |00042]"`},
+		{`format("hello %s", "world")`, `"hello world"`},
+		{`format("%s %s", [1, "a"], true)`, `"[1, "a"] true"`},
+		{`format("%v", "a")`, `""a""`},
+		{`format("%-4v|", 1)`, `"1   |"`},
+		{"format(\"%q\", \"a\tb\")", `""a\tb""`},
+		{`format("%x %X %x", 255, 255, "hi")`, `"ff FF 6869"`},
+		{`format("%#x", 255)`, `"0xff"`},
+		{`format("%.2f", float("3.14159"))`, `"3.14"`},
+		{`format("%8.3f|", 2)`, `"   2.000|"`},
+		{`format("100%%")`, `"100%"`},
+		{`format("%d", "a")`, "ERROR: format: %d can't format STRING"},
+		{`format("%f", "a")`, "ERROR: format: %f can't format STRING"},
+		{`format("%q", 1)`, "ERROR: format: %q can't format INTEGER"},
+		{`format("%y", 1)`, "ERROR: format: unknown verb %y"},
+		{`format("%é", 1)`, "ERROR: format: unknown verb %é"},
+		{`format("%dñ%s", 1, "ü")`, `"1ñü"`},
+		{`format("%d %d", 1)`, "ERROR: format: missing argument for %d"},
+		{`format("%d", 1, 2)`, "ERROR: format: too many arguments. got=2, used=1"},
+		{`format("50%")`, `ERROR: format: incomplete verb "%" at the end of the format string`},
+		{`format(1)`, "ERROR: first argument to `format` must be STRING, got INTEGER"},
+		{`format()`, "ERROR: wrong number of arguments. got=0, want at least 1"},
+	}
+
+	testInspect(t, tests)
+
+	var stdout bytes.Buffer
+	ctx := object.NewContext(DefaultBuiltins())
+	ctx.Stdout = &stdout
+	evaluated := testEvalWithContext(`printf("%s=%d", "x", 1); printf("!")`, ctx)
+	testNullObject(t, evaluated)
+	if stdout.String() != "x=1!" {
+		t.Errorf("wrong printf output. got=%q", stdout.String())
+	}
+}
+
+func TestOutputBuiltins(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedStdout string
+		expectedStderr string
+	}{
+		{`puts(1, "two")`, "1\n\"two\"\n", ""},
+		{`print(1, 2); print(3)`, "123", ""},
+		{`eprint("oops")`, "", "\"oops\""},
+		{`puts(); print(); eprint()`, "", ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		ctx := object.NewContext(DefaultBuiltins())
+		ctx.Stdout = &stdout
+		ctx.Stderr = &stderr
+
+		evaluated := testEvalWithContext(tt.input, ctx)
+		testNullObject(t, evaluated)
+
+		if stdout.String() != tt.expectedStdout {
+			t.Errorf("wrong stdout for %q. expected=%q, got=%q",
+				tt.input, tt.expectedStdout, stdout.String())
+		}
+		if stderr.String() != tt.expectedStderr {
+			t.Errorf("wrong stderr for %q. expected=%q, got=%q",
+				tt.input, tt.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestInputBuiltins(t *testing.T) {
+	tests := []struct {
+		input          string
+		stdin          string
+		expected       string
+		expectedStdout string
+	}{
+		{`[readLine(), readLine(), readLine()]`, "one\ntwo", `["one", "two", null]`, ""},
+		{`readLine()`, "windows\r\n", `"windows"`, ""},
+		{`readLine()`, "", "null", ""},
+		{`input("name? ")`, "Monkey\n", `"Monkey"`, "name? "},
+		{`let first = readLine(); [first, readAll()]`, "a\nb\nc\n", "[\"a\", \"b\nc\n\"]", ""},
+		{`readAll()`, "", `""`, ""},
+		{`input(1)`, "", "ERROR: argument to `input` must be STRING, got INTEGER", ""},
+		{`readLine(1)`, "", "ERROR: wrong number of arguments. got=1, want=0", ""},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		ctx := object.NewContext(DefaultBuiltins())
+		ctx.Stdin = strings.NewReader(tt.stdin)
+		ctx.Stdout = &stdout
+
+		evaluated := testEvalWithContext(tt.input, ctx)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q",
+				tt.input, tt.expected, evaluated.Inspect())
+		}
+		if stdout.String() != tt.expectedStdout {
+			t.Errorf("wrong stdout for %q. expected=%q, got=%q",
+				tt.input, tt.expectedStdout, stdout.String())
+		}
+	}
+}
+
+func TestFileSystemBuiltins(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`exists("DIR/notes.txt")`, "false"},
+		{`writeFile("DIR/notes.txt", "one")`, "null"},
+		{`appendFile("DIR/notes.txt", "two")`, "null"},
+		{`readFile("DIR/notes.txt")`, `"onetwo"`},
+		{`exists("DIR/notes.txt")`, "true"},
+		{`mkdir("DIR/sub/inner")`, "null"},
+		{`listDir("DIR")`, `["notes.txt", "sub"]`},
+		{`remove("DIR/notes.txt")`, "null"},
+		{`readFile("DIR/notes.txt")`, "ERROR: could not read file: open DIR/notes.txt: no such file or directory"},
+		{`remove("DIR/sub")`, "ERROR: could not remove: remove DIR/sub: directory not empty"},
+		{`readFile("DIR/../outside.txt")`, "ERROR: access denied: DIR/../outside.txt is outside the allowed directories"},
+		{`readFile(1)`, "ERROR: first argument to `readFile` must be STRING, got INTEGER"},
+		{`writeFile("DIR/notes.txt", 1)`, "ERROR: second argument to `writeFile` must be STRING, got INTEGER"},
+		{`listDir()`, "ERROR: wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		ctx := object.NewContext(DefaultBuiltins())
+		ctx.FS = &object.FSPolicy{Roots: []string{dir}}
+
+		input := strings.ReplaceAll(tt.input, "DIR", dir)
+		expected := strings.ReplaceAll(tt.expected, "DIR", dir)
+		evaluated := testEvalWithContext(input, ctx)
+		if evaluated.Inspect() != expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q",
+				input, expected, evaluated.Inspect())
+		}
+	}
+
+	// Without a policy, and in read only mode.
+	evaluated := testEval(`readFile("` + dir + `/sub")`)
+	if evaluated.Inspect() != "ERROR: file system access is disabled" {
+		t.Errorf("wrong result without a policy. got=%q", evaluated.Inspect())
+	}
+	ctx := object.NewContext(DefaultBuiltins())
+	ctx.FS = &object.FSPolicy{Roots: []string{dir}, ReadOnly: true}
+	evaluated = testEvalWithContext(`mkdir("`+dir+`/other")`, ctx)
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("mkdir was allowed in read only mode. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestBuiltinRegistry(t *testing.T) {
+	builtins := DefaultBuiltins()
+	builtins.Register("math.double", func(ctx *object.Context, args ...object.Object) object.Object {
+		return &object.Integer{Value: args[0].(*object.Integer).Value * 2}
+	})
+	builtins.Remove("first")
+	ctx := object.NewContext(builtins)
+
+	evaluated := testEvalWithContext("math.double(len([1, 2]))", ctx)
+	testIntegerObject(t, evaluated, 4)
+
+	evaluated = testEvalWithContext("first([1, 2])", ctx)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "identifier not found: first" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+
+	// Other registries are unaffected.
+	testIntegerObject(t, testEval("first([1, 2])"), 1)
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
 	if obj != NULL {
 		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
@@ -473,12 +1136,33 @@ func testNullObject(t *testing.T, obj object.Object) bool {
 }
 
 func testEval(input string) object.Object {
+	return testEvalWithContext(input, object.NewContext(DefaultBuiltins()))
+}
+
+func testEvalWithContext(input string, ctx *object.Context) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	env := object.NewEnvironment()
 
-	return Eval(program, env)
+	return Eval(program, env, ctx)
+}
+
+// A test case for the result of evaluating some input, as shown by Inspect.
+type inspectTest struct {
+	input    string
+	expected string
+}
+
+func testInspect(t *testing.T, tests []inspectTest) {
+	t.Helper()
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q",
+				tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
 }
 
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
@@ -0,0 +1,158 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ManuelGarciaF/go-interpreter/evaluator"
+	"github.com/ManuelGarciaF/go-interpreter/lexer"
+	"github.com/ManuelGarciaF/go-interpreter/object"
+	"github.com/ManuelGarciaF/go-interpreter/parser"
+)
+
+// Interpreter wraps the lexer, parser and evaluator so host programs can run monkey code
+// without wiring them together by hand. Globals persist between calls to Eval.
+type Interpreter struct {
+	env     *object.Environment
+	ctx     *object.Context
+	sandbox bool
+}
+
+// Option configures an Interpreter created with New.
+type Option func(*Interpreter)
+
+// WithBuiltins replaces the default builtins with r.
+func WithBuiltins(r *object.BuiltinRegistry) Option {
+	return func(i *Interpreter) {
+		i.ctx.Builtins = r
+	}
+}
+
+// WithSandbox drops the unsafe builtins, for running untrusted code. It applies to the
+// registry given with WithBuiltins too, regardless of the order of the options.
+func WithSandbox() Option {
+	return func(i *Interpreter) {
+		i.sandbox = true
+	}
+}
+
+// WithStdin sets the reader used by the input builtins.
+func WithStdin(r io.Reader) Option {
+	return func(i *Interpreter) {
+		i.ctx.Stdin = r
+	}
+}
+
+// WithStdout sets the writer used by puts and print.
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.ctx.Stdout = w
+	}
+}
+
+// WithStderr sets the writer used by eprint.
+func WithStderr(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.ctx.Stderr = w
+	}
+}
+
+// WithFSPolicy allows the file system builtins to access what p permits. By default
+// they can't access anything.
+func WithFSPolicy(p *object.FSPolicy) Option {
+	return func(i *Interpreter) {
+		i.ctx.FS = p
+	}
+}
+
+// ParseError is returned when the source could not be parsed. It contains every error
+// reported by the parser.
+type ParseError struct {
+	Messages []string
+}
+
+func (e *ParseError) Error() string {
+	return "parse error: " + strings.Join(e.Messages, "; ")
+}
+
+// RuntimeError is returned when evaluation produced an *object.Error.
+type RuntimeError struct {
+	Object *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	if trace := e.Object.StackTrace(); trace != "" {
+		return "runtime error: " + e.Object.Message + "\n" + trace
+	}
+	return "runtime error: " + e.Object.Message
+}
+
+func New(opts ...Option) *Interpreter {
+	i := &Interpreter{
+		env: object.NewEnvironment(),
+		ctx: object.NewContext(evaluator.DefaultBuiltins()),
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.sandbox {
+		i.ctx.Builtins = i.ctx.Builtins.Sandbox()
+	}
+	return i
+}
+
+// Eval parses and evaluates src in the interpreter's global environment, returning the
+// value of the last statement.
+func (i *Interpreter) Eval(src string) (object.Object, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, &ParseError{Messages: p.Errors()}
+	}
+
+	result := evaluator.Eval(program, i.env, i.ctx)
+	if errObj, ok := result.(*object.Error); ok {
+		return nil, &RuntimeError{Object: errObj}
+	}
+
+	return result, nil
+}
+
+// EvalFile reads the file at path and evaluates its contents.
+func (i *Interpreter) EvalFile(path string) (object.Object, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return i.Eval(string(src))
+}
+
+// Set binds name to val in the global environment. It fails if name was bound with const.
+func (i *Interpreter) Set(name string, val object.Object) error {
+	return i.env.Set(name, val)
+}
+
+// SetConst binds name to val in the global environment, scripts can't change it.
+func (i *Interpreter) SetConst(name string, val object.Object) error {
+	return i.env.SetConst(name, val)
+}
+
+// Get looks up a global binding.
+func (i *Interpreter) Get(name string) (object.Object, bool) {
+	return i.env.Get(name)
+}
+
+// RegisterBuiltin makes fn callable from monkey code as name. Namespaced names like
+// "math.abs" are allowed.
+func (i *Interpreter) RegisterBuiltin(name string, fn object.BuiltinFunction) {
+	i.ctx.Builtins.Register(name, fn)
+}
+
+// Builtins returns the interpreter's builtin registry, which may be modified.
+func (i *Interpreter) Builtins() *object.BuiltinRegistry {
+	return i.ctx.Builtins
+}
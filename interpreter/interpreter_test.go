@@ -0,0 +1,215 @@
+package interpreter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ManuelGarciaF/go-interpreter/evaluator"
+	"github.com/ManuelGarciaF/go-interpreter/object"
+)
+
+func TestEval(t *testing.T) {
+	interp := New()
+
+	if _, err := interp.Eval("let add = fn(x, y) { x + y };"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Globals persist between calls.
+	result, err := interp.Eval("add(2, 3)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testIntegerObject(t, result, 5)
+}
+
+func TestEvalErrors(t *testing.T) {
+	interp := New()
+
+	_, err := interp.Eval("let = 5;")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("error is not *ParseError. got=%T (%v)", err, err)
+	}
+	if len(parseErr.Messages) == 0 {
+		t.Errorf("ParseError has no messages")
+	}
+
+	_, err = interp.Eval("5 + true")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("error is not *RuntimeError. got=%T (%v)", err, err)
+	}
+	if runtimeErr.Object.Message != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong error message. got=%q", runtimeErr.Object.Message)
+	}
+
+	_, err = interp.Eval("fn half(x) { x / true }; fn run() { half(2) }; run()")
+	expected := "runtime error: type mismatch: INTEGER / BOOLEAN\n\tin half\n\tin run"
+	if err == nil || err.Error() != expected {
+		t.Errorf("wrong error. expected=%q, got=%v", expected, err)
+	}
+}
+
+func TestEvalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.mk")
+	if err := os.WriteFile(path, []byte("let x = 20; x * 2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := New().EvalFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testIntegerObject(t, result, 40)
+
+	if _, err := New().EvalFile(filepath.Join(t.TempDir(), "missing.mk")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	interp := New()
+	interp.Set("answer", &object.Integer{Value: 42})
+
+	result, err := interp.Eval("let doubled = answer * 2;")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != nil {
+		t.Errorf("let statement returned a value. got=%T (%+v)", result, result)
+	}
+
+	doubled, ok := interp.Get("doubled")
+	if !ok {
+		t.Fatalf("doubled is not set")
+	}
+	testIntegerObject(t, doubled, 84)
+
+	if _, ok := interp.Get("missing"); ok {
+		t.Errorf("Get returned a value for an unbound name")
+	}
+}
+
+func TestHostNull(t *testing.T) {
+	interp := New()
+	interp.Set("nothing", &object.Null{})
+
+	result, err := interp.Eval(`[nothing ?? 1, nothing?.[0], nothing?.(1), nothing ? 1 : 2, if (nothing) { 1 } else { 2 }, !nothing]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "[1, null, null, 2, 2, true]" {
+		t.Errorf("wrong result. got=%q", result.Inspect())
+	}
+}
+
+func TestSetConst(t *testing.T) {
+	interp := New()
+	if err := interp.SetConst("limit", &object.Integer{Value: 10}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err := interp.Eval("limit = 20")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("error is not *RuntimeError. got=%T (%v)", err, err)
+	}
+	if runtimeErr.Object.Message != "cannot assign to constant: limit" {
+		t.Errorf("wrong error message. got=%q", runtimeErr.Object.Message)
+	}
+
+	if err := interp.Set("limit", &object.Integer{Value: 20}); !errors.Is(err, object.ErrConstant) {
+		t.Errorf("Set on a constant didn't fail with object.ErrConstant. got=%v", err)
+	}
+}
+
+func TestRegisterBuiltin(t *testing.T) {
+	interp := New()
+	interp.RegisterBuiltin("triple", func(ctx *object.Context, args ...object.Object) object.Object {
+		n := args[0].(*object.Integer)
+		return &object.Integer{Value: n.Value * 3}
+	})
+
+	result, err := interp.Eval("triple(7)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testIntegerObject(t, result, 21)
+}
+
+func TestBuiltinCallback(t *testing.T) {
+	interp := New()
+	// Calls its second argument twice on the first.
+	interp.RegisterBuiltin("twice", func(ctx *object.Context, args ...object.Object) object.Object {
+		once := evaluator.ApplyFunction(args[1], []object.Object{args[0]}, ctx)
+		return evaluator.ApplyFunction(args[1], []object.Object{once}, ctx)
+	})
+
+	result, err := interp.Eval("twice(3, fn(x) { x * x })")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testIntegerObject(t, result, 81)
+}
+
+func TestSandbox(t *testing.T) {
+	builtins := object.NewBuiltinRegistry()
+	builtins.Register("safe", func(ctx *object.Context, args ...object.Object) object.Object {
+		return &object.Integer{Value: 1}
+	})
+	builtins.RegisterUnsafe("unsafe", func(ctx *object.Context, args ...object.Object) object.Object {
+		return &object.Integer{Value: 2}
+	})
+
+	interp := New(WithBuiltins(builtins), WithSandbox())
+
+	result, err := interp.Eval("safe()")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testIntegerObject(t, result, 1)
+
+	if _, err := interp.Eval("unsafe()"); err == nil {
+		t.Errorf("unsafe builtin is available in a sandbox")
+	}
+
+	// The order of the options doesn't matter.
+	interp = New(WithSandbox(), WithBuiltins(builtins))
+	if _, err := interp.Eval("unsafe()"); err == nil {
+		t.Errorf("unsafe builtin is available when WithSandbox comes first")
+	}
+}
+
+func TestOutputStreams(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	interp := New(WithStdout(&stdout), WithStderr(&stderr))
+
+	if _, err := interp.Eval(`puts("out"); eprint("err")`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stdout.String() != "\"out\"\n" {
+		t.Errorf("wrong stdout. got=%q", stdout.String())
+	}
+	if stderr.String() != "\"err\"" {
+		t.Errorf("wrong stderr. got=%q", stderr.String())
+	}
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d",
+			result.Value, expected)
+		return false
+	}
+
+	return true
+}
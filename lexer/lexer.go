@@ -1,6 +1,8 @@
 package lexer
 
 import (
+	"strings"
+
 	"github.com/ManuelGarciaF/go-interpreter/token"
 )
 
@@ -9,6 +11,12 @@ type Lexer struct {
 	position     int  // Pos of current char (ch)
 	readPosition int  // Pos of next char to read
 	ch           byte // Only ascii for now
+	line         int  // Line of the current char
+	lineStart    int  // Pos of the first char of the current line
+
+	// One entry per string interpolation we are inside of, with the number of '{' opened
+	// in it. That way we know which '}' closes the interpolation.
+	interpolations []int
 }
 
 const EOF byte = 0
@@ -19,12 +27,18 @@ func New(input string) *Lexer {
 		position:     0,
 		readPosition: 0,
 		ch:           0,
+		line:         1,
 	}
 	l.readChar() // Have to initialize with a first read
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.lineStart = l.readPosition
+	}
+
 	// If out of bounds
 	if l.readPosition >= len(l.input) {
 		l.ch = EOF
@@ -37,10 +51,18 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	line, column := l.line, l.position-l.lineStart+1
+	tok := l.readToken()
+	tok.Line = line
+	tok.Column = column
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	// NOTE: Could simplify this by extracting all the simple cases into a map
 	switch l.ch {
 	case '=':
@@ -49,6 +71,9 @@ func (l *Lexer) NextToken() token.Token {
 			// Advance a char.
 			l.readChar()
 			tok = token.New(token.EQ, string(first)+string(l.ch))
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.New(token.ARROW, "=>")
 		} else {
 			tok = token.New(token.ASSIGN, string(l.ch))
 		}
@@ -68,11 +93,50 @@ func (l *Lexer) NextToken() token.Token {
 	case '/':
 		tok = token.New(token.SLASH, string(l.ch))
 	case '*':
-		tok = token.New(token.ASTERISK, string(l.ch))
+		if l.peekChar() == '*' {
+			l.readChar()
+			tok = token.New(token.POWER, "**")
+		} else {
+			tok = token.New(token.ASTERISK, string(l.ch))
+		}
 	case '<':
-		tok = token.New(token.LT, string(l.ch))
+		if l.peekChar() == '<' {
+			l.readChar()
+			tok = token.New(token.SHIFT_LEFT, "<<")
+		} else {
+			tok = token.New(token.LT, string(l.ch))
+		}
 	case '>':
-		tok = token.New(token.GT, string(l.ch))
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.New(token.SHIFT_RIGHT, ">>")
+		} else {
+			tok = token.New(token.GT, string(l.ch))
+		}
+	case '&':
+		tok = token.New(token.AMPERSAND, string(l.ch))
+	case '|':
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.New(token.PIPE_FORWARD, "|>")
+		} else {
+			tok = token.New(token.PIPE, string(l.ch))
+		}
+	case '^':
+		tok = token.New(token.CARET, string(l.ch))
+	case '~':
+		tok = token.New(token.TILDE, string(l.ch))
+	case '?':
+		switch l.peekChar() {
+		case '?':
+			l.readChar()
+			tok = token.New(token.NULL_COALESCE, "??")
+		case '.':
+			l.readChar()
+			tok = token.New(token.OPTIONAL_CHAIN, "?.")
+		default:
+			tok = token.New(token.QUESTION, string(l.ch))
+		}
 	case ',':
 		tok = token.New(token.COMMA, string(l.ch))
 	case ';':
@@ -84,21 +148,34 @@ func (l *Lexer) NextToken() token.Token {
 	case ')':
 		tok = token.New(token.RPAREN, string(l.ch))
 	case '{':
+		if len(l.interpolations) > 0 {
+			l.interpolations[len(l.interpolations)-1]++
+		}
 		tok = token.New(token.LBRACE, string(l.ch))
 	case '}':
-		tok = token.New(token.RBRACE, string(l.ch))
+		last := len(l.interpolations) - 1
+		if last >= 0 && l.interpolations[last] == 0 {
+			// The end of an interpolation, continue reading the string.
+			l.interpolations = l.interpolations[:last]
+			tok = l.readStringPart(token.STRING_MIDDLE, token.STRING_TAIL)
+		} else {
+			if last >= 0 {
+				l.interpolations[last]--
+			}
+			tok = token.New(token.RBRACE, string(l.ch))
+		}
 	case '[':
 		tok = token.New(token.LBRACKET, string(l.ch))
 	case ']':
 		tok = token.New(token.RBRACKET, string(l.ch))
 	case '"':
-		// There are cases in which we don't find a complete string.
-		string, ok := l.readString()
-		if ok {
-			tok = token.New(token.STRING, string)
+		if strings.HasPrefix(l.input[l.position:], `"""`) {
+			tok = l.readMultilineString()
 		} else {
-			tok = token.New(token.EOF, "")
+			tok = l.readStringPart(token.STRING_HEAD, token.STRING)
 		}
+	case '`':
+		tok = l.readRawString()
 
 	case EOF:
 		tok = token.New(token.EOF, "")
@@ -108,7 +185,7 @@ func (l *Lexer) NextToken() token.Token {
 			literal := l.readIdentifier()
 			// We return early so we don't advance an extra character.
 			return token.New(token.LookupIdentifier(literal), literal)
-		} else if isDigit(l.ch) { // Check for ints.
+		} else if isDigit(l.ch) { // Check for ints, the parser validates them.
 			num := l.readNumber()
 			// We return early so we don't advance an extra character.
 			return token.New(token.INT, num)
@@ -127,7 +204,8 @@ func (l *Lexer) readIdentifier() string {
 	initialPos := l.position
 
 	// We already checked the first one is exclusively a letter before.
-	for isValidInIdentifier(l.ch) {
+	// Dots followed by a letter are allowed for namespaced builtins, like "math.abs".
+	for isValidInIdentifier(l.ch) || (l.ch == '.' && isLetter(l.peekChar())) {
 		l.readChar()
 	}
 
@@ -138,27 +216,123 @@ func (l *Lexer) readIdentifier() string {
 func (l *Lexer) readNumber() string {
 	initialPos := l.position
 
-	// No support for floats
-	for isDigit(l.ch) {
+	// No support for floats.
+	// Letters and underscores are read too, for literals like 0xFF or 1_000.
+	for isValidInIdentifier(l.ch) {
 		l.readChar()
 	}
 	// The current ch is not part of the identifier, so we use l.position.
 	return l.input[initialPos:l.position]
 }
 
-// Returns the string, and ok. ok is false if a closing '"' couldnt' be found.
-func (l *Lexer) readString() (string, bool) {
-	// Advance the first '"'
+// Reads a string from the current '"' or '}' up to the closing '"' or the next "${".
+// Returns a token of type interpolated in the second case, or complete in the first.
+func (l *Lexer) readStringPart(interpolated, complete token.TokenType) token.Token {
+	// Advance the first '"' or '}'
 	l.readChar()
 
 	start := l.position
 	for l.ch != '"' {
+		if l.ch == '$' && l.peekChar() == '{' {
+			literal := l.input[start:l.position]
+			l.readChar() // The '{' is skipped by NextToken
+			l.interpolations = append(l.interpolations, 0)
+			return token.New(interpolated, literal)
+		}
+		if l.ch == EOF {
+			// There are cases in which we don't find a complete string.
+			return token.New(token.EOF, "")
+		}
 		l.readChar()
+	}
+	return token.New(complete, l.input[start:l.position])
+}
+
+// Reads a string between backticks. It can span multiple lines and its contents are
+// taken as is.
+func (l *Lexer) readRawString() token.Token {
+	// Advance the opening '`'
+	l.readChar()
+
+	start := l.position
+	for l.ch != '`' {
 		if l.ch == EOF {
-			return "", false
+			return token.New(token.EOF, "")
 		}
+		l.readChar()
+	}
+	return token.New(token.RAW_STRING, l.input[start:l.position])
+}
+
+// Reads a string between triple quotes, leaves ch on the last '"'.
+func (l *Lexer) readMultilineString() token.Token {
+	start := l.position + len(`"""`)
+	end := strings.Index(l.input[start:], `"""`)
+	if end == -1 {
+		l.position = len(l.input)
+		l.readPosition = len(l.input)
+		l.ch = EOF
+		return token.New(token.EOF, "")
 	}
-	return l.input[start:l.position], true
+	end += start
+
+	for l.position < end+len(`"""`)-1 {
+		l.readChar()
+	}
+	return token.New(token.MULTILINE_STRING, stripIndent(l.input[start:end]))
+}
+
+// Removes the line break after the opening quotes, the last line if it only has the
+// indentation of the closing quotes, and the indentation common to all non blank lines.
+// This allows writing
+//
+//	let query = """
+//	    SELECT *
+//	    FROM users
+//	    """;
+//
+// and getting "SELECT *\nFROM users".
+func stripIndent(s string) string {
+	s = strings.TrimPrefix(s, "\r")
+	s = strings.TrimPrefix(s, "\n")
+	lines := strings.Split(s, "\n")
+	if len(lines) > 1 && isBlank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := ""
+	first := true
+	for _, line := range lines {
+		if isBlank(line) {
+			continue
+		}
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			indent = lineIndent
+			first = false
+			continue
+		}
+		// Keep the common prefix.
+		i := 0
+		for i < len(indent) && i < len(lineIndent) && indent[i] == lineIndent[i] {
+			i++
+		}
+		indent = indent[:i]
+	}
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, indent) {
+			lines[i] = line[len(indent):]
+		} else {
+			// Only blank lines can have less indentation.
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimLeft(line, " \t\r") == ""
 }
 
 func (l *Lexer) skipWhitespace() {
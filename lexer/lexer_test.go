@@ -30,6 +30,15 @@ if (5 < 10) {
 "foo bar"
 [1, 2];
 {"foo": "bar"}
+math.abs(x);
+"hi ${name}!"
+"${a} and ${ {"k": "${b}"}["k"] }"
+a & b | c ^ ~d << 1 >> 2;
+2 ** 3 * 4;
+a ? b : c ?? d?.[e]?.(f);
+xs |> f | g;
+x => x >= 1;
+const y = 1;
 `
 
 	tests := []struct {
@@ -122,6 +131,81 @@ if (5 < 10) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
+		{token.IDENTIFIER, "math.abs"},
+		{token.LPAREN, "("},
+		{token.IDENTIFIER, "x"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.STRING_HEAD, "hi "},
+		{token.IDENTIFIER, "name"},
+		{token.STRING_TAIL, "!"},
+		{token.STRING_HEAD, ""},
+		{token.IDENTIFIER, "a"},
+		{token.STRING_MIDDLE, " and "},
+		{token.LBRACE, "{"},
+		{token.STRING, "k"},
+		{token.COLON, ":"},
+		{token.STRING_HEAD, ""},
+		{token.IDENTIFIER, "b"},
+		{token.STRING_TAIL, ""},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.STRING, "k"},
+		{token.RBRACKET, "]"},
+		{token.STRING_TAIL, ""},
+		{token.IDENTIFIER, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENTIFIER, "b"},
+		{token.PIPE, "|"},
+		{token.IDENTIFIER, "c"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENTIFIER, "d"},
+		{token.SHIFT_LEFT, "<<"},
+		{token.INT, "1"},
+		{token.SHIFT_RIGHT, ">>"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "2"},
+		{token.POWER, "**"},
+		{token.INT, "3"},
+		{token.ASTERISK, "*"},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENTIFIER, "a"},
+		{token.QUESTION, "?"},
+		{token.IDENTIFIER, "b"},
+		{token.COLON, ":"},
+		{token.IDENTIFIER, "c"},
+		{token.NULL_COALESCE, "??"},
+		{token.IDENTIFIER, "d"},
+		{token.OPTIONAL_CHAIN, "?."},
+		{token.LBRACKET, "["},
+		{token.IDENTIFIER, "e"},
+		{token.RBRACKET, "]"},
+		{token.OPTIONAL_CHAIN, "?."},
+		{token.LPAREN, "("},
+		{token.IDENTIFIER, "f"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.IDENTIFIER, "xs"},
+		{token.PIPE_FORWARD, "|>"},
+		{token.IDENTIFIER, "f"},
+		{token.PIPE, "|"},
+		{token.IDENTIFIER, "g"},
+		{token.SEMICOLON, ";"},
+		{token.IDENTIFIER, "x"},
+		{token.ARROW, "=>"},
+		{token.IDENTIFIER, "x"},
+		{token.GT, ">"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.CONST, "const"},
+		{token.IDENTIFIER, "y"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -141,3 +225,87 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestRawAndMultilineStrings(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"`a \"b\" ${c}`", token.RAW_STRING, "a \"b\" ${c}"},
+		{"`line 1\n  line 2\\n`", token.RAW_STRING, "line 1\n  line 2\\n"},
+		{"``", token.RAW_STRING, ""},
+		{"\"\"\"one line\"\"\"", token.MULTILINE_STRING, "one line"},
+		{"\"\"\"\n    {\n      \"a\": 1\n    }\n    \"\"\"", token.MULTILINE_STRING, "{\n  \"a\": 1\n}"},
+		{"\"\"\"\n\t\tfirst\n\n\t\t\tsecond\n\t\"\"\"", token.MULTILINE_STRING, "first\n\n\tsecond"},
+		{"\"\"\"\n  a\n  b\"\"\"", token.MULTILINE_STRING, "a\nb"},
+		{"\"\"\"\"\"\"", token.MULTILINE_STRING, ""},
+		{"\"\"", token.STRING, ""},
+		{"`unterminated", token.EOF, ""},
+		{"\"\"\"unterminated\"\"", token.EOF, ""},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%v, got=%v",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after the string, got=%v", i, next.Type)
+		}
+	}
+}
+
+func TestIntegerLiterals(t *testing.T) {
+	input := "0xFF 0o755 0b1010 1_000_000 12abc"
+	expected := []string{"0xFF", "0o755", "0b1010", "1_000_000", "12abc"}
+
+	l := New(input)
+	for i, literal := range expected {
+		tok := l.NextToken()
+		if tok.Type != token.INT || tok.Literal != literal {
+			t.Fatalf("tests[%d] - wrong token. expected=INT %q, got=%v %q",
+				i, literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  `a\nb` + \"\"\"\n  c\n  \"\"\"\n\tfoo"
+
+	tests := []struct {
+		expectedLiteral string
+		line            int
+		column          int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"a\nb", 2, 3},
+		{"+", 3, 4},
+		{"c", 3, 6},
+		{"foo", 6, 2},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.line, tt.column, tok.Line, tok.Column)
+		}
+	}
+}
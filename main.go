@@ -1,11 +1,26 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/ManuelGarciaF/go-interpreter/interpreter"
+	"github.com/ManuelGarciaF/go-interpreter/object"
 	"github.com/ManuelGarciaF/go-interpreter/repl"
 )
 
+// Scripts started by the user get the same file system access as the user.
+var unrestrictedFS = &object.FSPolicy{Roots: []string{"/"}}
+
 func main() {
-	repl.Start(os.Stdin, os.Stdout)
+	// Without arguments, start the REPL. Otherwise run the given script.
+	if len(os.Args) < 2 {
+		repl.Start(os.Stdin, os.Stdout)
+		return
+	}
+
+	if _, err := interpreter.New(interpreter.WithFSPolicy(unrestrictedFS)).EvalFile(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,44 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// Context holds the state shared by a whole evaluation, as opposed to the Environment,
+// which changes with every scope. It is passed to every builtin call.
+type Context struct {
+	Builtins *BuiltinRegistry
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Checked by the file system builtins, nil disables them.
+	FS *FSPolicy
+
+	// Buffers Stdin, so it must be kept between reads.
+	stdinReader *bufio.Reader
+	bufferedIn  io.Reader // The reader wrapped by stdinReader
+}
+
+// NewContext returns a context using the process' standard streams.
+func NewContext(builtins *BuiltinRegistry) *Context {
+	return &Context{
+		Builtins: builtins,
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	}
+}
+
+// StdinReader returns a buffered reader over Stdin. The same reader is returned as long as
+// Stdin doesn't change, so no buffered input is lost between calls.
+func (c *Context) StdinReader() *bufio.Reader {
+	if c.stdinReader == nil || c.bufferedIn != c.Stdin {
+		c.stdinReader = bufio.NewReader(c.Stdin)
+		c.bufferedIn = c.Stdin
+	}
+	return c.stdinReader
+}
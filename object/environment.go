@@ -1,11 +1,22 @@
 package object
 
-import ()
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrConstant is returned when rebinding or assigning a name bound with const.
+	ErrConstant = errors.New("cannot assign to constant")
+	// ErrNotFound is returned by Assign when the name is not bound.
+	ErrNotFound = errors.New("identifier not found")
+)
 
 func NewEnvironment() *Environment {
 	return &Environment{
-		store: make(map[string]Object),
-		outer: nil,
+		store:     make(map[string]Object),
+		constants: make(map[string]bool),
+		outer:     nil,
 	}
 }
 
@@ -16,8 +27,9 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store     map[string]Object
+	constants map[string]bool // Names in store that were bound with const
+	outer     *Environment
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -28,8 +40,36 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
-func (e *Environment) Set(name string, val Object) Object {
+// Set binds name to val in this scope, replacing the previous binding unless it is a
+// constant. Names in outer scopes are shadowed, even constants.
+func (e *Environment) Set(name string, val Object) error {
+	if e.constants[name] {
+		return fmt.Errorf("%w: %s", ErrConstant, name)
+	}
 	e.store[name] = val
-	return val
+	return nil
+}
 
+// SetConst is like Set, but the binding can't be changed afterwards.
+func (e *Environment) SetConst(name string, val Object) error {
+	if err := e.Set(name, val); err != nil {
+		return err
+	}
+	e.constants[name] = true
+	return nil
+}
+
+// Assign changes the value of an existing binding, in the innermost scope that has it.
+func (e *Environment) Assign(name string, val Object) error {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; !ok {
+			continue
+		}
+		if env.constants[name] {
+			return fmt.Errorf("%w: %s", ErrConstant, name)
+		}
+		env.store[name] = val
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, name)
 }
@@ -0,0 +1,111 @@
+package object
+
+// Equaler is implemented by the objects that are compared by value with ==. Objects that
+// don't implement it are only equal to themselves.
+type Equaler interface {
+	// Equal reports whether the object is equal to other. Containers must compare their
+	// elements with c.Equal, so cyclic structures terminate.
+	Equal(other Object, c *Comparison) bool
+}
+
+// Comparison keeps track of the pairs of objects being compared in a deep comparison.
+type Comparison struct {
+	inProgress map[[2]Object]bool
+}
+
+// Equal reports whether a and b are deeply equal.
+func Equal(a, b Object) bool {
+	return (&Comparison{}).Equal(a, b)
+}
+
+func (c *Comparison) Equal(a, b Object) bool {
+	if a == b {
+		return true
+	}
+	eq, ok := a.(Equaler)
+	if !ok {
+		return false
+	}
+
+	// If we get back to a pair we are already comparing, there is a cycle. Any difference
+	// will be found by the comparison in progress, so it can be assumed equal here.
+	pair := [2]Object{a, b}
+	if c.inProgress[pair] {
+		return true
+	}
+	if c.inProgress == nil {
+		c.inProgress = make(map[[2]Object]bool)
+	}
+	c.inProgress[pair] = true
+	defer delete(c.inProgress, pair)
+
+	return eq.Equal(b, c)
+}
+
+// Integers and floats are compared numerically, like the == operator does.
+func (i *Integer) Equal(other Object, _ *Comparison) bool {
+	switch o := other.(type) {
+	case *Integer:
+		return i.Value == o.Value
+	case *Float:
+		return float64(i.Value) == o.Value
+	default:
+		return false
+	}
+}
+
+func (f *Float) Equal(other Object, _ *Comparison) bool {
+	switch o := other.(type) {
+	case *Float:
+		return f.Value == o.Value
+	case *Integer:
+		return f.Value == float64(o.Value)
+	default:
+		return false
+	}
+}
+
+func (s *String) Equal(other Object, _ *Comparison) bool {
+	o, ok := other.(*String)
+	return ok && s.Value == o.Value
+}
+
+func (b *Boolean) Equal(other Object, _ *Comparison) bool {
+	o, ok := other.(*Boolean)
+	return ok && b.Value == o.Value
+}
+
+func (*Null) Equal(other Object, _ *Comparison) bool {
+	_, ok := other.(*Null)
+	return ok
+}
+
+func (a *Array) Equal(other Object, c *Comparison) bool {
+	o, ok := other.(*Array)
+	if !ok || len(a.Elements) != len(o.Elements) {
+		return false
+	}
+
+	for i := range a.Elements {
+		if !c.Equal(a.Elements[i], o.Elements[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Hashes are equal if they have the same pairs, regardless of their order.
+func (h *Hash) Equal(other Object, c *Comparison) bool {
+	o, ok := other.(*Hash)
+	if !ok || h.Len() != o.Len() {
+		return false
+	}
+
+	for _, pair := range h.pairs {
+		value, ok := o.Get(pair.Key.(Hashable))
+		if !ok || !c.Equal(pair.Value, value) {
+			return false
+		}
+	}
+	return true
+}
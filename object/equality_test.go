@@ -0,0 +1,61 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b     Object
+		expected bool
+	}{
+		{&Integer{Value: 1}, &Integer{Value: 1}, true},
+		{&Integer{Value: 1}, &String{Value: "1"}, false},
+		{&Boolean{Value: true}, &Boolean{Value: true}, true},
+		{&Null{}, &Null{}, true},
+		{
+			&Array{Elements: []Object{&String{Value: "a"}, &Null{}}},
+			&Array{Elements: []Object{&String{Value: "a"}, &Null{}}},
+			true,
+		},
+		{
+			&Array{Elements: []Object{&String{Value: "a"}}},
+			&Array{Elements: []Object{&String{Value: "b"}}},
+			false,
+		},
+		{&Error{Message: "a"}, &Error{Message: "a"}, false},
+	}
+
+	for _, tt := range tests {
+		if Equal(tt.a, tt.b) != tt.expected {
+			t.Errorf("Equal(%s, %s) is not %t", tt.a.Inspect(), tt.b.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestEqualCycles(t *testing.T) {
+	// a = [1, a] and b = [1, b]
+	a := &Array{Elements: []Object{&Integer{Value: 1}, nil}}
+	a.Elements[1] = a
+	b := &Array{Elements: []Object{&Integer{Value: 1}, nil}}
+	b.Elements[1] = b
+	// c = [2, c]
+	c := &Array{Elements: []Object{&Integer{Value: 2}, nil}}
+	c.Elements[1] = c
+
+	if !Equal(a, b) {
+		t.Errorf("equal cyclic arrays are not Equal")
+	}
+	if Equal(a, c) {
+		t.Errorf("different cyclic arrays are Equal")
+	}
+
+	// A hash containing itself.
+	h1 := NewHash(0)
+	h1.Set(&String{Value: "self"}, h1)
+	h2 := NewHash(0)
+	h2.Set(&String{Value: "self"}, h2)
+	if !Equal(h1, h2) {
+		t.Errorf("equal cyclic hashes are not Equal")
+	}
+}
@@ -0,0 +1,54 @@
+package object
+
+// Freeze makes obj and every array and hash inside it immutable, so index assignment on
+// them fails. Other objects are immutable already.
+func Freeze(obj Object) {
+	switch obj := obj.(type) {
+	case *Array:
+		// Everything inside a frozen value is frozen, this also stops at cycles.
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, el := range obj.Elements {
+			Freeze(el)
+		}
+	case *Hash:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		// Keys are frozen when they are added.
+		for _, pair := range obj.pairs {
+			Freeze(pair.Value)
+		}
+	}
+}
+
+// Returns a frozen deep copy of obj, or obj itself if it was frozen already. obj must not
+// contain cycles, which hashable objects never do.
+func frozenCopy(obj Object) Object {
+	switch obj := obj.(type) {
+	case *Array:
+		if obj.Frozen {
+			return obj
+		}
+		elements := make([]Object, len(obj.Elements))
+		for i, el := range obj.Elements {
+			elements[i] = frozenCopy(el)
+		}
+		return &Array{Elements: elements, Frozen: true}
+	case *Hash:
+		if obj.Frozen {
+			return obj
+		}
+		c := NewHash(obj.Len())
+		for _, pair := range obj.pairs {
+			c.Set(pair.Key.(Hashable), frozenCopy(pair.Value))
+		}
+		c.Frozen = true
+		return c
+	default:
+		return obj
+	}
+}
@@ -0,0 +1,79 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// FSPolicy restricts what the file system builtins may access. A nil policy denies every
+// access.
+type FSPolicy struct {
+	Roots    []string // Directories under which access is allowed
+	ReadOnly bool
+}
+
+// Check returns an error if the policy doesn't allow accessing path. write must be true
+// for operations that modify the file system.
+func (p *FSPolicy) Check(path string, write bool) error {
+	if p == nil {
+		return errors.New("file system access is disabled")
+	}
+	if write && p.ReadOnly {
+		return fmt.Errorf("cannot modify %s: file system access is read-only", path)
+	}
+
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	for _, root := range p.Roots {
+		resolvedRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		if isInside(resolvedRoot, resolved) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("access denied: %s is outside the allowed directories", path)
+}
+
+// Returns the absolute path with symlinks evaluated, so they can't be used to escape a
+// root. Paths that don't exist yet are resolved through their closest existing parent.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	missing := make([]string, 0)
+	for {
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err == nil {
+			parts := append([]string{resolved}, missing...)
+			return filepath.Join(parts...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs { // Reached the root without finding anything.
+			return abs, nil
+		}
+		missing = append([]string{filepath.Base(abs)}, missing...)
+		abs = parent
+	}
+}
+
+func isInside(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,47 @@
+package object
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFSPolicy(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	// A symlink inside the root pointing outside of it.
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := &FSPolicy{Roots: []string{root}}
+	readOnly := &FSPolicy{Roots: []string{root}, ReadOnly: true}
+
+	tests := []struct {
+		policy  *FSPolicy
+		path    string
+		write   bool
+		allowed bool
+	}{
+		{policy, filepath.Join(root, "file.txt"), false, true},
+		{policy, filepath.Join(root, "new", "dir", "file.txt"), true, true},
+		{policy, root, false, true},
+		{policy, filepath.Join(root, "..", "escape.txt"), false, false},
+		{policy, filepath.Join(outside, "file.txt"), false, false},
+		{policy, filepath.Join(link, "file.txt"), false, false},
+		{readOnly, filepath.Join(root, "file.txt"), false, true},
+		{readOnly, filepath.Join(root, "file.txt"), true, false},
+		{nil, filepath.Join(root, "file.txt"), false, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.policy.Check(tt.path, tt.write)
+		if tt.allowed && err != nil {
+			t.Errorf("access to %s (write=%t) denied: %s", tt.path, tt.write, err)
+		}
+		if !tt.allowed && err == nil {
+			t.Errorf("access to %s (write=%t) allowed", tt.path, tt.write)
+		}
+	}
+}
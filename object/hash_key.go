@@ -0,0 +1,90 @@
+package object
+
+// Arrays and hashes are hashed by value. Since they can be modified, Hash.Set stores a
+// frozen copy of them as the key, so changing the original doesn't change the stored key.
+
+// AsHashable returns obj as a Hashable if it can be used as a hash key. Arrays and hashes
+// can only be used if all their elements can, and they don't contain themselves.
+func AsHashable(obj Object) (Hashable, bool) {
+	if !canHash(obj, make(map[Object]bool)) {
+		return nil, false
+	}
+	return obj.(Hashable), true
+}
+
+func canHash(obj Object, visiting map[Object]bool) bool {
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] {
+			return false
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		for _, el := range obj.Elements {
+			if !canHash(el, visiting) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		if visiting[obj] {
+			return false
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		// Keys were already checked when they were added.
+		for _, pair := range obj.pairs {
+			if !canHash(pair.Value, visiting) {
+				return false
+			}
+		}
+		return true
+	default:
+		_, ok := obj.(Hashable)
+		return ok
+	}
+}
+
+// HashKey combines the keys of the elements, so their order matters. Use AsHashable first,
+// elements that can't be hashed only contribute their type.
+func (a *Array) HashKey() HashKey {
+	h := compositeSeed
+	for _, el := range a.Elements {
+		h = mix(h, elementHash(el))
+	}
+	return HashKey{Type: a.Type(), Value: h}
+}
+
+// HashKey combines the keys and values of the pairs without depending on their order, since
+// equal hashes may have been built in different orders.
+func (h *Hash) HashKey() HashKey {
+	var sum uint64
+	for _, pair := range h.pairs {
+		sum += mix(mix(compositeSeed, elementHash(pair.Key)), elementHash(pair.Value))
+	}
+	return HashKey{Type: h.Type(), Value: mix(compositeSeed, sum)}
+}
+
+const fnvPrime uint64 = 1099511628211
+
+// Combines two hashes in an order dependent way. Starting from compositeSeed keeps the
+// result unpredictable even when the element hashes, like those of integers, are not.
+func mix(h, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= v & 0xff
+		h *= fnvPrime
+		v >>= 8
+	}
+	return h
+}
+
+func elementHash(obj Object) uint64 {
+	hashable, ok := obj.(Hashable)
+	if !ok {
+		return uint64(obj.Type())
+	}
+	key := hashable.HashKey()
+	return mix(uint64(key.Type), key.Value)
+}
@@ -2,7 +2,8 @@ package object
 
 import (
 	"fmt"
-	"hash/fnv"
+	"hash/maphash"
+	"strconv"
 	"strings"
 
 	"github.com/ManuelGarciaF/go-interpreter/ast"
@@ -12,6 +13,7 @@ type ObjectType int
 
 const (
 	INTEGER_OBJ ObjectType = iota
+	FLOAT_OBJ
 	STRING_OBJ
 	ARRAY_OBJ
 	HASH_OBJ
@@ -26,6 +28,7 @@ const (
 // For pretty printing the enum values
 var objectTypeStrings = map[ObjectType]string{
 	INTEGER_OBJ:      "INTEGER",
+	FLOAT_OBJ:        "FLOAT",
 	STRING_OBJ:       "STRING",
 	ARRAY_OBJ:        "ARRAY",
 	HASH_OBJ:         "HASH",
@@ -47,6 +50,7 @@ type Object interface {
 }
 
 type Hashable interface {
+	Object
 	HashKey() HashKey
 }
 
@@ -63,6 +67,20 @@ func (*Integer) Type() ObjectType   { return INTEGER_OBJ }
 func (i *Integer) Inspect() string  { return fmt.Sprint(i.Value) }
 func (i *Integer) HashKey() HashKey { return HashKey{Type: i.Type(), Value: uint64(i.Value)} }
 
+type Float struct {
+	Value float64
+}
+
+func (*Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	// Always show a decimal point, so floats can be told apart from integers.
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
 type String struct {
 	Value string
 }
@@ -70,19 +88,28 @@ type String struct {
 func (*String) Type() ObjectType  { return STRING_OBJ }
 func (s *String) Inspect() string { return fmt.Sprint("\"" + s.Value + "\"") }
 func (s *String) HashKey() HashKey {
-	// Hash the string using FNV-64.
-	// In an ideal world, something would be done about collisions.
-	h := fnv.New64()
-	h.Write([]byte(s.Value))
-
 	return HashKey{
 		Type:  s.Type(),
-		Value: h.Sum64(),
+		Value: hashString(s.Value),
 	}
 }
 
+// The seed is random for each process, so scripts can't craft keys that collide on purpose.
+// Collisions are still handled by Hash, this only keeps them rare.
+var stringSeed = maphash.MakeSeed()
+
+// Starting value for the keys of arrays and hashes, random for the same reason. A variable so
+// tests can replace it.
+var compositeSeed = maphash.String(stringSeed, "composite")
+
+// A variable so tests can force collisions.
+var hashString = func(s string) uint64 {
+	return maphash.String(stringSeed, s)
+}
+
 type Array struct {
 	Elements []Object
+	Frozen   bool // Set by Freeze
 }
 
 func (*Array) Type() ObjectType { return ARRAY_OBJ }
@@ -106,16 +133,107 @@ type HashPair struct {
 	Value Object
 }
 
+// Hash keeps its pairs in insertion order, while still providing O(1) lookups. Keys with
+// the same HashKey share a bucket and are told apart by comparing their values.
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	buckets map[HashKey][]int // Positions in pairs of the keys with each HashKey
+	pairs   []HashPair
+	Frozen  bool // Set by Freeze
+}
+
+func NewHash(size int) *Hash {
+	return &Hash{
+		buckets: make(map[HashKey][]int, size),
+		pairs:   make([]HashPair, 0, size),
+	}
+}
+
+// Set adds a pair at the end of the hash. If the key was already present, its value is
+// replaced and it keeps its original position. Array and hash keys are stored as frozen
+// copies, so changing the original later doesn't break the hash. Set doesn't check Frozen.
+func (h *Hash) Set(key Hashable, value Object) {
+	key = frozenCopy(key).(Hashable)
+
+	if i, ok := h.find(key); ok {
+		h.pairs[i].Value = value
+		return
+	}
+
+	hashKey := key.HashKey()
+	h.buckets[hashKey] = append(h.buckets[hashKey], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	i, ok := h.find(key)
+	if !ok {
+		return nil, false
+	}
+	return h.pairs[i].Value, true
+}
+
+// Delete removes key from the hash, the remaining pairs keep their order.
+func (h *Hash) Delete(key Hashable) {
+	i, ok := h.find(key)
+	if !ok {
+		return
+	}
+	h.pairs = append(h.pairs[:i], h.pairs[i+1:]...)
+
+	// Every position after the removed pair moved back by one.
+	for hashKey, bucket := range h.buckets {
+		updated := make([]int, 0, len(bucket))
+		for _, pos := range bucket {
+			switch {
+			case pos < i:
+				updated = append(updated, pos)
+			case pos > i:
+				updated = append(updated, pos-1)
+			}
+		}
+		if len(updated) == 0 {
+			delete(h.buckets, hashKey)
+		} else {
+			h.buckets[hashKey] = updated
+		}
+	}
+}
+
+// Copy returns a shallow copy of the hash, the keys and values are shared.
+func (h *Hash) Copy() *Hash {
+	c := NewHash(h.Len())
+	for _, pair := range h.pairs {
+		c.Set(pair.Key.(Hashable), pair.Value)
+	}
+	return c
+}
+
+// Pairs returns the pairs in insertion order. The slice must not be modified.
+func (h *Hash) Pairs() []HashPair {
+	return h.pairs
+}
+
+func (h *Hash) Len() int {
+	return len(h.pairs)
+}
+
+// Returns the position of key in pairs.
+func (h *Hash) find(key Hashable) (int, bool) {
+	for _, i := range h.buckets[key.HashKey()] {
+		// Keys with the same HashKey may still be different.
+		if Equal(h.pairs[i].Key, key) {
+			return i, true
+		}
+	}
+	return 0, false
 }
 
 func (*Hash) Type() ObjectType { return HASH_OBJ }
 func (h *Hash) Inspect() string {
 	var sb strings.Builder
 
-	pairs := make([]string, 0, len(h.Pairs))
-	for _, pair := range h.Pairs {
+	pairs := make([]string, 0, len(h.pairs))
+	for _, pair := range h.pairs {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
 	}
 
@@ -160,6 +278,7 @@ func (*ReturnValue) Type() ObjectType   { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string { return rv.Value.Inspect() }
 
 type Function struct {
+	Name       string // Empty for anonymous functions
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
@@ -174,7 +293,11 @@ func (f *Function) Inspect() string {
 		params = append(params, p.String())
 	}
 
-	sb.WriteString("fn(")
+	sb.WriteString("fn")
+	if f.Name != "" {
+		sb.WriteString(" " + f.Name)
+	}
+	sb.WriteString("(")
 	sb.WriteString(strings.Join(params, ", "))
 	sb.WriteString(") {\n")
 	sb.WriteString(f.Body.String())
@@ -183,10 +306,11 @@ func (f *Function) Inspect() string {
 	return sb.String()
 }
 
-type BuiltinFunction func(args ...Object) Object
+type BuiltinFunction func(ctx *Context, args ...Object) Object
 
 type Builtin struct {
-	Fn BuiltinFunction
+	Fn     BuiltinFunction
+	Unsafe bool // Whether it has effects outside the interpreter
 }
 
 func (*Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -194,7 +318,28 @@ func (*Builtin) Inspect() string  { return "builtin function" }
 
 type Error struct {
 	Message string
+	// Names of the functions the error went through, innermost first. Anonymous
+	// functions have an empty name.
+	Stack []string
 }
 
 func (*Error) Type() ObjectType  { return ERROR_OBJ }
 func (e *Error) Inspect() string { return "ERROR: " + e.Message }
+
+// StackTrace returns a line like "\tin fib" for each function in Stack, or an empty
+// string if the error happened outside of any function.
+func (e *Error) StackTrace() string {
+	var sb strings.Builder
+
+	for i, name := range e.Stack {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if name == "" {
+			name = "<anonymous>"
+		}
+		sb.WriteString("\tin " + name)
+	}
+
+	return sb.String()
+}
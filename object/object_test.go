@@ -1,6 +1,7 @@
 package object
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -51,3 +52,207 @@ func TestBooleanHashKey(t *testing.T) {
         t.Errorf("Booleans with different content have same hash keys")
     }
 }
+
+func TestHashOrder(t *testing.T) {
+	h := NewHash(0)
+	h.Set(&String{Value: "b"}, &Integer{Value: 1})
+	h.Set(&Integer{Value: 10}, &Integer{Value: 2})
+	h.Set(&String{Value: "a"}, &Integer{Value: 3})
+	// Replacing a value keeps the original position.
+	h.Set(&String{Value: "b"}, &Integer{Value: 4})
+
+	expected := `{"b": 4, 10: 2, "a": 3}`
+	if h.Inspect() != expected {
+		t.Errorf("wrong Inspect output. expected=%q, got=%q", expected, h.Inspect())
+	}
+	if h.Len() != 3 {
+		t.Errorf("wrong length. got=%d", h.Len())
+	}
+
+	value, ok := h.Get(&Integer{Value: 10})
+	if !ok || value.(*Integer).Value != 2 {
+		t.Errorf("wrong value for key 10. got=%+v", value)
+	}
+	if _, ok := h.Get(&String{Value: "missing"}); ok {
+		t.Errorf("got a value for a missing key")
+	}
+}
+
+func TestHashCollisions(t *testing.T) {
+	// Every string gets the same HashKey.
+	original := hashString
+	hashString = func(string) uint64 { return 42 }
+	defer func() { hashString = original }()
+
+	a := &String{Value: "a"}
+	b := &String{Value: "b"}
+	c := &String{Value: "c"}
+	if a.HashKey() != b.HashKey() {
+		t.Fatalf("the hash function was not replaced")
+	}
+
+	h := NewHash(0)
+	h.Set(a, &Integer{Value: 1})
+	h.Set(b, &Integer{Value: 2})
+	h.Set(c, &Integer{Value: 3})
+	h.Set(&String{Value: "b"}, &Integer{Value: 4})
+
+	expected := `{"a": 1, "b": 4, "c": 3}`
+	if h.Inspect() != expected {
+		t.Errorf("wrong Inspect output. expected=%q, got=%q", expected, h.Inspect())
+	}
+
+	h.Delete(&String{Value: "a"})
+	expected = `{"b": 4, "c": 3}`
+	if h.Inspect() != expected {
+		t.Errorf("wrong Inspect output after Delete. expected=%q, got=%q", expected, h.Inspect())
+	}
+	value, ok := h.Get(&String{Value: "c"})
+	if !ok || value.(*Integer).Value != 3 {
+		t.Errorf("wrong value for key c. got=%+v", value)
+	}
+	if _, ok := h.Get(&String{Value: "a"}); ok {
+		t.Errorf("got a value for a deleted key")
+	}
+
+	// Composite keys made of colliding elements collide too, and are still kept apart.
+	arrayA := &Array{Elements: []Object{a, &Integer{Value: 1}}}
+	arrayB := &Array{Elements: []Object{b, &Integer{Value: 1}}}
+	if arrayA.HashKey() != arrayB.HashKey() {
+		t.Fatalf("arrays of colliding elements have different hash keys")
+	}
+	h = NewHash(0)
+	h.Set(arrayA, &Integer{Value: 1})
+	h.Set(arrayB, &Integer{Value: 2})
+	expected = `{["a", 1]: 1, ["b", 1]: 2}`
+	if h.Inspect() != expected {
+		t.Errorf("wrong Inspect output for array keys. expected=%q, got=%q", expected, h.Inspect())
+	}
+
+	// Keys of arrays and hashes depend on the seed, not only on their elements.
+	ints := &Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: 2}}}
+	hash := NewHash(0)
+	hash.Set(&Integer{Value: 1}, &Integer{Value: 2})
+	arrayKey, hashKey := ints.HashKey(), hash.HashKey()
+
+	originalSeed := compositeSeed
+	compositeSeed++
+	defer func() { compositeSeed = originalSeed }()
+	if ints.HashKey() == arrayKey {
+		t.Errorf("array hash key doesn't depend on the seed")
+	}
+	if hash.HashKey() == hashKey {
+		t.Errorf("hash hash key doesn't depend on the seed")
+	}
+}
+
+func TestCompositeHashKey(t *testing.T) {
+	pair1 := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+	pair2 := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+	swapped := &Array{Elements: []Object{&String{Value: "a"}, &Integer{Value: 1}}}
+	if pair1.HashKey() != pair2.HashKey() {
+		t.Errorf("arrays with same content have different hash keys")
+	}
+	if pair1.HashKey() == swapped.HashKey() {
+		t.Errorf("arrays with different order have same hash keys")
+	}
+
+	// Hashes don't depend on the insertion order.
+	hash1 := NewHash(0)
+	hash1.Set(&String{Value: "x"}, &Integer{Value: 1})
+	hash1.Set(&String{Value: "y"}, pair1)
+	hash2 := NewHash(0)
+	hash2.Set(&String{Value: "y"}, pair2)
+	hash2.Set(&String{Value: "x"}, &Integer{Value: 1})
+	if hash1.HashKey() != hash2.HashKey() {
+		t.Errorf("hashes with same content have different hash keys")
+	}
+
+	if _, ok := AsHashable(pair1); !ok {
+		t.Errorf("array of hashable elements is not hashable")
+	}
+	withFunction := &Array{Elements: []Object{&Function{}}}
+	if _, ok := AsHashable(withFunction); ok {
+		t.Errorf("array containing a function is hashable")
+	}
+	cyclic := &Array{Elements: []Object{nil}}
+	cyclic.Elements[0] = cyclic
+	if _, ok := AsHashable(cyclic); ok {
+		t.Errorf("array containing itself is hashable")
+	}
+}
+
+func TestEnvironmentConstants(t *testing.T) {
+	env := NewEnvironment()
+	one := &Integer{Value: 1}
+
+	if err := env.SetConst("x", one); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := env.Set("x", one); !errors.Is(err, ErrConstant) {
+		t.Errorf("Set on a constant didn't fail with ErrConstant. got=%v", err)
+	}
+	if err := env.SetConst("x", one); !errors.Is(err, ErrConstant) {
+		t.Errorf("SetConst on a constant didn't fail with ErrConstant. got=%v", err)
+	}
+
+	inner := NewEnclosedEnvironment(env)
+	if err := inner.Assign("x", one); !errors.Is(err, ErrConstant) {
+		t.Errorf("Assign on an outer constant didn't fail with ErrConstant. got=%v", err)
+	}
+	if err := inner.Set("x", one); err != nil {
+		t.Errorf("shadowing a constant failed: %s", err)
+	}
+	if err := inner.Assign("missing", one); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Assign on a missing name didn't fail with ErrNotFound. got=%v", err)
+	}
+
+	env.Set("y", one)
+	two := &Integer{Value: 2}
+	if err := inner.Assign("y", two); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if y, _ := env.Get("y"); y != two {
+		t.Errorf("Assign didn't change the outer binding. got=%s", y.Inspect())
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	inner := &Array{Elements: []Object{&Integer{Value: 1}}}
+	hash := NewHash(1)
+	hash.Set(&String{Value: "inner"}, inner)
+	outer := &Array{Elements: []Object{hash}}
+	// Cycles are fine.
+	hash.Set(&String{Value: "outer"}, outer)
+
+	Freeze(outer)
+	if !outer.Frozen || !hash.Frozen || !inner.Frozen {
+		t.Errorf("Freeze is not deep. outer=%t, hash=%t, inner=%t", outer.Frozen, hash.Frozen, inner.Frozen)
+	}
+}
+
+func TestHashKeysAreCopied(t *testing.T) {
+	key := &Array{Elements: []Object{&Integer{Value: 1}}}
+	hash := NewHash(1)
+	hash.Set(key, &Boolean{Value: true})
+
+	stored := hash.Pairs()[0].Key.(*Array)
+	if stored == key {
+		t.Fatalf("the key was not copied")
+	}
+	if !stored.Frozen || key.Frozen {
+		t.Errorf("only the copy should be frozen. stored=%t, key=%t", stored.Frozen, key.Frozen)
+	}
+
+	key.Elements[0] = &Integer{Value: 2}
+	if _, ok := hash.Get(&Array{Elements: []Object{&Integer{Value: 1}}}); !ok {
+		t.Errorf("changing the original key affected the hash")
+	}
+
+	// Frozen keys are used as they are.
+	frozen := &Array{Frozen: true}
+	hash.Set(frozen, &Boolean{Value: true})
+	if hash.Pairs()[1].Key != frozen {
+		t.Errorf("a frozen key was copied")
+	}
+}
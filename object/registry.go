@@ -0,0 +1,67 @@
+package object
+
+import (
+	"sort"
+)
+
+// BuiltinRegistry holds the builtin functions visible to an interpreter. Names may be
+// namespaced with dots, as in "math.abs".
+type BuiltinRegistry struct {
+	builtins map[string]*Builtin
+}
+
+func NewBuiltinRegistry() *BuiltinRegistry {
+	return &BuiltinRegistry{
+		builtins: make(map[string]*Builtin),
+	}
+}
+
+// Register adds fn under name, replacing any builtin already registered with that name.
+func (r *BuiltinRegistry) Register(name string, fn BuiltinFunction) {
+	r.builtins[name] = &Builtin{Fn: fn}
+}
+
+// RegisterUnsafe is like Register, but marks the builtin as reaching outside the
+// interpreter (files, processes, etc.), so it is left out of sandboxed registries.
+func (r *BuiltinRegistry) RegisterUnsafe(name string, fn BuiltinFunction) {
+	r.builtins[name] = &Builtin{Fn: fn, Unsafe: true}
+}
+
+func (r *BuiltinRegistry) Remove(name string) {
+	delete(r.builtins, name)
+}
+
+func (r *BuiltinRegistry) Get(name string) (*Builtin, bool) {
+	b, ok := r.builtins[name]
+	return b, ok
+}
+
+// Names returns the registered names in alphabetical order.
+func (r *BuiltinRegistry) Names() []string {
+	names := make([]string, 0, len(r.builtins))
+	for name := range r.builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clone returns a copy of the registry that can be modified independently.
+func (r *BuiltinRegistry) Clone() *BuiltinRegistry {
+	clone := NewBuiltinRegistry()
+	for name, b := range r.builtins {
+		clone.builtins[name] = b
+	}
+	return clone
+}
+
+// Sandbox returns a copy of the registry without the unsafe builtins.
+func (r *BuiltinRegistry) Sandbox() *BuiltinRegistry {
+	sandbox := NewBuiltinRegistry()
+	for name, b := range r.builtins {
+		if !b.Unsafe {
+			sandbox.builtins[name] = b
+		}
+	}
+	return sandbox
+}
@@ -0,0 +1,37 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinRegistry(t *testing.T) {
+	noop := func(ctx *Context, args ...Object) Object { return nil }
+
+	r := NewBuiltinRegistry()
+	r.Register("len", noop)
+	r.Register("math.abs", noop)
+	r.RegisterUnsafe("exec", noop)
+
+	expected := []string{"exec", "len", "math.abs"}
+	if names := r.Names(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("wrong names. expected=%v, got=%v", expected, names)
+	}
+
+	sandbox := r.Sandbox()
+	if _, ok := sandbox.Get("exec"); ok {
+		t.Errorf("sandbox contains an unsafe builtin")
+	}
+	if _, ok := sandbox.Get("math.abs"); !ok {
+		t.Errorf("sandbox is missing a safe builtin")
+	}
+
+	clone := r.Clone()
+	clone.Remove("len")
+	if _, ok := clone.Get("len"); ok {
+		t.Errorf("builtin was not removed from the clone")
+	}
+	if _, ok := r.Get("len"); !ok {
+		t.Errorf("removing from a clone modified the original registry")
+	}
+}
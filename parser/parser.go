@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/ManuelGarciaF/go-interpreter/ast"
 	"github.com/ManuelGarciaF/go-interpreter/lexer"
@@ -20,6 +23,9 @@ type Parser struct {
 
 	currToken token.Token
 	peekToken token.Token
+	// Tokens already read from the lexer that come after peekToken, for the few places
+	// where one token of lookahead is not enough.
+	buffered []token.Token
 
 	// We associate prefix and infix functions to each token.
 	// We save them in maps inside the parser to 'bind' the functions to the parser.
@@ -29,29 +35,68 @@ type Parser struct {
 
 type precedence int
 
-// Order of precedences for operator parsing
+// Order of precedences for operator parsing. Bitwise operators follow C, so & | and ^
+// bind looser than comparisons.
 const (
 	LOWEST      precedence = iota
+	ASSIGNMENT             // x = y
+	TERNARY                // a ? b : c
+	PIPELINE               // a |> f()
+	COALESCE               // a ?? b
+	BIT_OR                 // |
+	BIT_XOR                // ^
+	BIT_AND                // &
 	EQUALS                 // ==
 	LESSGREATER            // > or <
+	SHIFT                  // << or >>
 	SUM                    // +
 	PRODUCT                // *
-	PREFIX                 // -x or !x
+	PREFIX                 // -x, !x or ~x
+	POWER                  // **, so -2 ** 2 is -(2 ** 2)
 	CALL                   // x()
 	INDEX                  // x[y]
 )
 
 var precedences = map[token.TokenType]precedence{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.EQ:             EQUALS,
+	token.NOT_EQ:         EQUALS,
+	token.LT:             LESSGREATER,
+	token.GT:             LESSGREATER,
+	token.PIPE:           BIT_OR,
+	token.CARET:          BIT_XOR,
+	token.AMPERSAND:      BIT_AND,
+	token.SHIFT_LEFT:     SHIFT,
+	token.SHIFT_RIGHT:    SHIFT,
+	token.PLUS:           SUM,
+	token.MINUS:          SUM,
+	token.SLASH:          PRODUCT,
+	token.ASTERISK:       PRODUCT,
+	token.POWER:          POWER,
+	token.LPAREN:         CALL,
+	token.LBRACKET:       INDEX,
+	token.QUESTION:       TERNARY,
+	token.NULL_COALESCE:  COALESCE,
+	token.PIPE_FORWARD:   PIPELINE,
+	token.ASSIGN:         ASSIGNMENT,
+	token.OPTIONAL_CHAIN: INDEX,
+}
+
+// Operators not listed here are left associative.
+var rightAssociative = map[token.TokenType]bool{
+	token.POWER:    true,
+	token.QUESTION: true,
+	token.ASSIGN:   true,
+}
+
+// Returns the precedence to parse the right side of an infix operator with. It is one lower
+// for right associative operators, so the right side takes the following operators with the
+// same precedence and a ** b ** c is a ** (b ** c).
+func rightPrecedence(tt token.TokenType) precedence {
+	prec := precedences[tt]
+	if rightAssociative[tt] {
+		prec--
+	}
+	return prec
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -70,8 +115,12 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns[token.IDENTIFIER] = p.parseIdentifier
 	p.prefixParseFns[token.INT] = p.parseIntegerLiteral
 	p.prefixParseFns[token.STRING] = p.parseStringLiteral
+	p.prefixParseFns[token.RAW_STRING] = p.parseStringLiteral
+	p.prefixParseFns[token.MULTILINE_STRING] = p.parseStringLiteral
+	p.prefixParseFns[token.STRING_HEAD] = p.parseInterpolatedString
 	p.prefixParseFns[token.BANG] = p.parsePrefixExpression
 	p.prefixParseFns[token.MINUS] = p.parsePrefixExpression
+	p.prefixParseFns[token.TILDE] = p.parsePrefixExpression
 	p.prefixParseFns[token.TRUE] = p.parseBoolean
 	p.prefixParseFns[token.FALSE] = p.parseBoolean
 	p.prefixParseFns[token.LPAREN] = p.parseGroupedExpression
@@ -84,19 +133,47 @@ func New(l *lexer.Lexer) *Parser {
 	p.infixParseFns[token.MINUS] = p.parseInfixExpression
 	p.infixParseFns[token.SLASH] = p.parseInfixExpression
 	p.infixParseFns[token.ASTERISK] = p.parseInfixExpression
+	p.infixParseFns[token.POWER] = p.parseInfixExpression
 	p.infixParseFns[token.EQ] = p.parseInfixExpression
 	p.infixParseFns[token.NOT_EQ] = p.parseInfixExpression
 	p.infixParseFns[token.LT] = p.parseInfixExpression
 	p.infixParseFns[token.GT] = p.parseInfixExpression
+	p.infixParseFns[token.AMPERSAND] = p.parseInfixExpression
+	p.infixParseFns[token.PIPE] = p.parseInfixExpression
+	p.infixParseFns[token.CARET] = p.parseInfixExpression
+	p.infixParseFns[token.SHIFT_LEFT] = p.parseInfixExpression
+	p.infixParseFns[token.SHIFT_RIGHT] = p.parseInfixExpression
 	p.infixParseFns[token.LPAREN] = p.parseCallExpression
 	p.infixParseFns[token.LBRACKET] = p.parseIndexExpression
+	p.infixParseFns[token.QUESTION] = p.parseConditionalExpression
+	p.infixParseFns[token.NULL_COALESCE] = p.parseNullCoalescingExpression
+	p.infixParseFns[token.OPTIONAL_CHAIN] = p.parseOptionalChain
+	p.infixParseFns[token.PIPE_FORWARD] = p.parsePipeExpression
+	p.infixParseFns[token.ASSIGN] = p.parseAssignExpression
 
 	return p
 }
 
 func (p *Parser) nextToken() {
 	p.currToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+	if len(p.buffered) > 0 {
+		p.peekToken = p.buffered[0]
+		p.buffered = p.buffered[1:]
+	} else {
+		p.peekToken = p.l.NextToken()
+	}
+}
+
+// Returns the token n positions after peekToken without consuming anything, so
+// peekAhead(0) is peekToken.
+func (p *Parser) peekAhead(n int) token.Token {
+	if n == 0 {
+		return p.peekToken
+	}
+	for len(p.buffered) < n {
+		p.buffered = append(p.buffered, p.l.NextToken())
+	}
+	return p.buffered[n-1]
 }
 
 func (p *Parser) Errors() []string {
@@ -121,10 +198,16 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.FUNCTION:
+		// Only named functions are statements, fn(x) { x } is still an expression.
+		if p.peekTokenIs(token.IDENTIFIER) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -133,7 +216,7 @@ func (p *Parser) parseStatement() ast.Statement {
 func (p *Parser) parseLetStatement() ast.Statement {
 	statement := &ast.LetStatement{Token: p.currToken}
 
-	// At this point, curr = LET, peek should be an IDENTIFIER.
+	// At this point, curr = LET or CONST, peek should be an IDENTIFIER.
 	if !p.expectPeek(token.IDENTIFIER) {
 		return nil
 	}
@@ -158,6 +241,22 @@ func (p *Parser) parseLetStatement() ast.Statement {
 	return statement
 }
 
+func (p *Parser) parseFunctionStatement() ast.Statement {
+	statement := &ast.FunctionStatement{Token: p.currToken}
+
+	literal, ok := p.parseFunctionLiteral().(*ast.FunctionLiteral)
+	if !ok {
+		return nil
+	}
+	statement.Function = literal
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
 func (p *Parser) parseReturnStatement() ast.Statement {
 	statement := &ast.ReturnStatement{Token: p.currToken}
 
@@ -208,15 +307,33 @@ func (p *Parser) parseExpression(precedence precedence) ast.Expression {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	// x => x * 2
+	if p.peekTokenIs(token.ARROW) {
+		literal := &ast.FunctionLiteral{Token: p.peekToken, Parameters: []*ast.Identifier{ident}}
+		p.nextToken()
+		literal.Body = p.parseArrowBody()
+		return literal
+	}
+
+	return ident
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	literal := &ast.IntegerLiteral{Token: p.currToken}
-	value, err := strconv.ParseInt(p.currToken.Literal, 10, 64)
+	value, err := parseInteger(p.currToken.Literal)
+	if errors.Is(err, strconv.ErrRange) {
+		p.errors = append(p.errors,
+			fmt.Sprintf("Integer %s at line %d, column %d is too large, the maximum is %d",
+				p.currToken.Literal, p.currToken.Line, p.currToken.Column, int64(math.MaxInt64)),
+		)
+		return nil
+	}
 	if err != nil {
 		p.errors = append(p.errors,
-			fmt.Sprintf("Could not parse %q as an integer", p.currToken.Literal),
+			fmt.Sprintf("Could not parse %q as an integer at line %d, column %d",
+				p.currToken.Literal, p.currToken.Line, p.currToken.Column),
 		)
 		return nil
 	}
@@ -226,10 +343,71 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return literal
 }
 
+// Parses decimal, hexadecimal (0x), octal (0o) and binary (0b) integers. Digits can be
+// separated by single underscores, like 1_000_000. Unlike Go, a leading 0 doesn't mean
+// octal.
+func parseInteger(literal string) (int64, error) {
+	base := 10
+	digits := literal
+	if len(literal) > 2 && literal[0] == '0' {
+		switch literal[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			digits = literal[2:]
+		}
+	}
+
+	if strings.HasPrefix(digits, "_") || strings.HasSuffix(digits, "_") ||
+		strings.Contains(digits, "__") {
+		return 0, strconv.ErrSyntax
+	}
+
+	return strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64)
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal}
 }
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.currToken}
+	str.Parts = make([]ast.Expression, 0)
+	str.Parts = p.appendStringPart(str.Parts)
+
+	for {
+		// Skip over the string part and parse the embedded expression
+		p.nextToken()
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		// After the expression, the string either continues with another one or ends
+		if p.peekTokenIs(token.STRING_MIDDLE) {
+			p.nextToken()
+			str.Parts = p.appendStringPart(str.Parts)
+			continue
+		}
+		if !p.expectPeek(token.STRING_TAIL) {
+			return nil
+		}
+		str.Parts = p.appendStringPart(str.Parts)
+
+		return str
+	}
+}
+
+// Appends the current string token to parts, unless it's empty.
+func (p *Parser) appendStringPart(parts []ast.Expression) []ast.Expression {
+	if p.currToken.Literal == "" {
+		return parts
+	}
+	return append(parts, &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal})
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.currToken}
 	array.Elements = p.parseExpressionList(token.RBRACKET)
@@ -238,7 +416,7 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.currToken}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
+	hash.Pairs = make([]ast.HashLiteralPair, 0)
 
 	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
@@ -252,13 +430,13 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 
-		hash.Pairs[key] = value
+		hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Key: key, Value: value})
 
 		// Only expectPeek if we are at the closing brace.
 		if !p.peekTokenIs(token.RBRACE) {
 			// Separated into two ifs to make the side effect explicit.
 			if !p.expectPeek(token.COMMA) {
-				return  nil
+				return nil
 			}
 		}
 	}
@@ -275,6 +453,11 @@ func (p *Parser) parseBoolean() ast.Expression {
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	// (a, b) => a + b
+	if p.isArrowParameterList() {
+		return p.parseArrowFunction()
+	}
+
 	p.nextToken() // Advance the starting LPAREN
 
 	// Parse an expression with the lowest precedence, since we inside parethesis
@@ -307,7 +490,7 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Left:     left,
 		Operator: p.currToken.Literal,
 	}
-	precedence := p.currPrecedence()
+	precedence := rightPrecedence(p.currToken.Type)
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
@@ -353,7 +536,13 @@ func (p *Parser) parseIfExpression() ast.Expression {
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	literal := &ast.FunctionLiteral{Token: p.currToken}
 
-	// There should be a paren after the "fn" token
+	// fn name(x) { ... }
+	if p.peekTokenIs(token.IDENTIFIER) {
+		p.nextToken()
+		literal.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	}
+
+	// There should be a paren after the "fn" token or the name
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
@@ -370,6 +559,57 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return literal
 }
 
+// Checks if the '(' in currToken starts the parameters of an arrow function, that is,
+// a list of identifiers followed by ") =>".
+func (p *Parser) isArrowParameterList() bool {
+	i := 0
+	if p.peekAhead(i).Type != token.RPAREN {
+		for {
+			if p.peekAhead(i).Type != token.IDENTIFIER {
+				return false
+			}
+			i++
+			if p.peekAhead(i).Type != token.COMMA {
+				break
+			}
+			i++
+		}
+		if p.peekAhead(i).Type != token.RPAREN {
+			return false
+		}
+	}
+	return p.peekAhead(i+1).Type == token.ARROW
+}
+
+func (p *Parser) parseArrowFunction() ast.Expression {
+	parameters := p.parseFunctionParameters()
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	literal := &ast.FunctionLiteral{Token: p.currToken, Parameters: parameters}
+	literal.Body = p.parseArrowBody()
+	return literal
+}
+
+// Parses what comes after the "=>", either a block or a single expression, which is
+// wrapped in a block.
+func (p *Parser) parseArrowBody() *ast.BlockStatement {
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		return p.parseBlockStatement()
+	}
+
+	block := &ast.BlockStatement{Token: p.currToken}
+	p.nextToken()
+	statement := &ast.ExpressionStatement{Token: p.currToken}
+	statement.Expression = p.parseExpression(LOWEST)
+	block.Statements = []ast.Statement{statement}
+
+	return block
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	b := &ast.BlockStatement{
 		Token:      p.currToken,
@@ -433,14 +673,115 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	return exp
 }
 
-// the left side of the bracket is the array expression, the right is the index
-func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.currToken, Left: left}
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	exp := &ast.ConditionalExpression{Token: p.currToken, Condition: condition}
+
+	// Skip over the '?'
+	p.nextToken()
+	exp.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+	// Right associative, a ? b : c ? d : e is a ? b : (c ? d : e)
+	exp.Alternative = p.parseExpression(rightPrecedence(exp.Token.Type))
+
+	return exp
+}
+
+func (p *Parser) parseNullCoalescingExpression(left ast.Expression) ast.Expression {
+	exp := &ast.NullCoalescingExpression{Token: p.currToken, Left: left}
+
+	p.nextToken()
+	exp.Right = p.parseExpression(rightPrecedence(exp.Token.Type))
+
+	return exp
+}
+
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	exp := &ast.AssignExpression{Token: p.currToken, Target: target}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	default:
+		p.errors = append(p.errors, fmt.Sprintf("Cannot assign to %s", target))
+		return nil
+	}
+
+	p.nextToken()
+	// Right associative, a = b = c is a = (b = c)
+	exp.Value = p.parseExpression(rightPrecedence(exp.Token.Type))
+
+	return exp
+}
+
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	exp := &ast.PipeExpression{Token: p.currToken, Left: left}
 
-	// Skip over the '['
 	p.nextToken()
+	exp.Right = p.parseExpression(rightPrecedence(exp.Token.Type))
 
-	exp.Index = p.parseExpression(LOWEST)
+	return exp
+}
+
+// Parses a?.[k] or f?.(x), the "?." is the current token.
+func (p *Parser) parseOptionalChain(left ast.Expression) ast.Expression {
+	chain := p.currToken
+
+	switch p.peekToken.Type {
+	case token.LBRACKET:
+		p.nextToken()
+		p.nextToken()
+		exp := &ast.OptionalIndexExpression{Token: chain, Left: left}
+		exp.Index = p.parseExpression(LOWEST)
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return exp
+	case token.LPAREN:
+		p.nextToken()
+		exp := &ast.OptionalCallExpression{Token: chain, Function: left}
+		exp.Arguments = p.parseExpressionList(token.RPAREN)
+		return exp
+	default:
+		p.errors = append(p.errors,
+			fmt.Sprintf("Expected [ or ( after ?., got %s", p.peekToken.Type),
+		)
+		return nil
+	}
+}
+
+// the left side of the bracket is the array expression, the right is the index or a slice
+// like [start:end:step], where every part is optional.
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	bracket := p.currToken
+
+	var start ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		// Skip over the '['
+		p.nextToken()
+		start = p.parseExpression(LOWEST)
+
+		// Not a slice, just an index
+		if p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			return &ast.IndexExpression{Token: bracket, Left: left, Index: start}
+		}
+	}
+
+	exp := &ast.SliceExpression{Token: bracket, Left: left, Start: start}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	exp.End = p.parseOptionalSlicePart()
+
+	// The step has its own ':'
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		exp.Step = p.parseOptionalSlicePart()
+	}
 
 	// Check for a closing ']'
 	if !p.expectPeek(token.RBRACKET) {
@@ -450,6 +791,15 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+// Parses the expression after a ':' in a slice, returns nil if it was omitted.
+func (p *Parser) parseOptionalSlicePart() ast.Expression {
+	if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.RBRACKET) {
+		return nil
+	}
+	p.nextToken()
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	args := make([]ast.Expression, 0)
 
@@ -498,13 +848,6 @@ func (p *Parser) expectPeek(tt token.TokenType) bool {
 	return true
 }
 
-func (p *Parser) currPrecedence() precedence {
-	if p, ok := precedences[p.currToken.Type]; ok {
-		return p
-	}
-	return LOWEST
-}
-
 func (p *Parser) peekPrecedence() precedence {
 	if p, ok := precedences[p.peekToken.Type]; ok {
 		return p
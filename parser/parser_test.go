@@ -6,6 +6,7 @@ import (
 
 	"github.com/ManuelGarciaF/go-interpreter/ast"
 	"github.com/ManuelGarciaF/go-interpreter/lexer"
+	"github.com/ManuelGarciaF/go-interpreter/token"
 )
 
 func TestLetStatements(t *testing.T) {
@@ -42,6 +43,47 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestConstAndAssignParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 5;", "const x = 5;"},
+		{"x = 5", "(x = 5)"},
+		{"a = b = c + 1", "(a = (b = (c + 1)))"},
+		{"xs[0] = y ? 1 : 2", "((xs[0]) = (y ? 1 : 2))"},
+		{"h[\"a\"][1] = x |> f", "(((h[\"a\"])[1]) = (x |> f))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	l := lexer.New("const y = 1;")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if let, ok := program.Statements[0].(*ast.LetStatement); !ok || let.Token.Type != token.CONST {
+		t.Errorf("const statement not parsed as a const *ast.LetStatement. got=%T", program.Statements[0])
+	}
+
+	for _, input := range []string{"1 = 2", "f() = 1", "a + b = c", "xs[1:2] = 3"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -140,6 +182,74 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0xFF_FF", 65535},
+		{"0755", 755},
+		{"9223372036854775807", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value wrong for %q. expected=%d, got=%d", tt.input, tt.expected, literal.Value)
+		}
+		// The original spelling is kept.
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() wrong. expected=%q, got=%q", tt.input, literal.String())
+		}
+	}
+}
+
+func TestIntegerLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1;\nlet y = 9223372036854775808;",
+			"Integer 9223372036854775808 at line 2, column 9 is too large, the maximum is 9223372036854775807"},
+		{"  0x1_0000_0000_0000_0000",
+			"Integer 0x1_0000_0000_0000_0000 at line 1, column 3 is too large, the maximum is 9223372036854775807"},
+		{"0b102", `Could not parse "0b102" as an integer at line 1, column 1`},
+		{"1__000", `Could not parse "1__000" as an integer at line 1, column 1`},
+		{"1_000_", `Could not parse "1_000_" as an integer at line 1, column 1`},
+		{"0x", `Could not parse "0x" as an integer at line 1, column 1`},
+		{"12abc", `Could not parse "12abc" as an integer at line 1, column 1`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected a parser error for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
 func TestStringLiteralExpression(t *testing.T) {
 	input := `"hello world";`
 
@@ -159,6 +269,137 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestConditionalAndOptionalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ? b", "Expected next token to be COLON, got EOF"},
+		{"a?.b", "Expected [ or ( after ?., got IDENTIFIER"},
+		{"a?.[b", "Expected next token to be RBRACKET, got EOF"},
+		{"? 1", "No prefix parse function for QUESTION"},
+		{"?? 1", "No prefix parse function for NULL_COALESCE"},
+		{"?.[1]", "No prefix parse function for OPTIONAL_CHAIN"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected a parser error for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestOperatorParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"& 1", "No prefix parse function for AMPERSAND"},
+		{"| 1", "No prefix parse function for PIPE"},
+		{"^ 1", "No prefix parse function for CARET"},
+		{"<< 1", "No prefix parse function for SHIFT_LEFT"},
+		{">> 1", "No prefix parse function for SHIFT_RIGHT"},
+		{"let x ~ 1", "Expected next token to be ASSIGN, got TILDE"},
+		{"** 2", "No prefix parse function for POWER"},
+		{"|> f", "No prefix parse function for PIPE_FORWARD"},
+		{"=> 1", "No prefix parse function for ARROW"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected a parser error for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestStringLiteralRoundTrip(t *testing.T) {
+	tests := []struct {
+		input    string
+		value    string
+		expected string
+	}{
+		{`"hello world"`, "hello world", `"hello world"`},
+		{"`raw ${x} \"quoted\"\n  line`", "raw ${x} \"quoted\"\n  line", "`raw ${x} \"quoted\"\n  line`"},
+		{"\"\"\"\n    SELECT *\n      FROM t\n    \"\"\"", "SELECT *\n  FROM t", "\"\"\"\nSELECT *\n  FROM t\n\"\"\""},
+	}
+
+	for _, tt := range tests {
+		for _, input := range []string{tt.input, tt.expected} {
+			l := lexer.New(input)
+			p := New(l)
+			program := p.ParseProgram()
+			checkParserErrors(t, p)
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			literal, ok := stmt.Expression.(*ast.StringLiteral)
+			if !ok {
+				t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+			}
+			if literal.Value != tt.value {
+				t.Errorf("literal.Value wrong for %q. expected=%q, got=%q", input, tt.value, literal.Value)
+			}
+			if literal.String() != tt.expected {
+				t.Errorf("literal.String() wrong for %q. expected=%q, got=%q", input, tt.expected, literal.String())
+			}
+		}
+	}
+}
+
+func TestInterpolatedStringParsing(t *testing.T) {
+	input := `"hello ${name}, you have ${len(items) + 1} items"`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+
+	if len(str.Parts) != 5 {
+		t.Fatalf("wrong number of parts. got=%d", len(str.Parts))
+	}
+	if !testIdentifier(t, str.Parts[1], "name") {
+		return
+	}
+	if str.Parts[3].String() != "(len(items) + 1)" {
+		t.Errorf("wrong embedded expression. got=%q", str.Parts[3].String())
+	}
+	expected := `"hello ${name}, you have ${(len(items) + 1)} items"`
+	if str.String() != expected {
+		t.Errorf("str.String() wrong. expected=%q, got=%q", expected, str.String())
+	}
+
+	for _, input := range []string{`"${x"`, `"${}"`} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 
@@ -203,13 +444,18 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		"two":   2,
 		"three": 3,
 	}
-	for key, value := range hash.Pairs {
-		literal, ok := key.(*ast.StringLiteral)
+	for _, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
 		if !ok {
-			t.Errorf("key is not ast.StringLiteral. got=%T", key)
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
 		}
-		expectedValue := expected[literal.String()]
-		testIntegerLiteral(t, value, expectedValue)
+		expectedValue := expected[literal.Value]
+		testIntegerLiteral(t, pair.Value, expectedValue)
+	}
+
+	// Pairs keep the order of the source.
+	if hash.String() != `{"one":1, "two":2, "three":3}` {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
 	}
 }
 
@@ -260,18 +506,18 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		},
 	}
 
-	for key, value := range hash.Pairs {
-		literal, ok := key.(*ast.StringLiteral)
+	for _, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
 		if !ok {
-			t.Errorf("key is not ast.StringLiteral. got=%T", key)
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
 			continue
 		}
-		testFunc, ok := tests[literal.String()]
+		testFunc, ok := tests[literal.Value]
 		if !ok {
-			t.Errorf("No test function for key %q found", literal.String())
+			t.Errorf("No test function for key %q found", literal.Value)
 			continue
 		}
-		testFunc(value)
+		testFunc(pair.Value)
 	}
 }
 
@@ -489,6 +735,70 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a & b == c",
+			"(a & (b == c))",
+		},
+		{
+			"1 << 2 + 3 < 4 >> 1",
+			"((1 << (2 + 3)) < (4 >> 1))",
+		},
+		{
+			"~a & -b",
+			"((~a) & (-b))",
+		},
+		{
+			"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))",
+		},
+		{
+			"a == 1 ? b + 1 : c ?? d",
+			"((a == 1) ? (b + 1) : (c ?? d))",
+		},
+		{
+			"a ?? b ?? c | d",
+			"((a ?? b) ?? (c | d))",
+		},
+		{
+			"a?.[b]?.(c, d) + e",
+			"((a?.[b])?.(c, d) + e)",
+		},
+		{
+			"{x ? 1 : 2: y}[a ? b : c]",
+			"({(x ? 1 : 2):y}[(a ? b : c)])",
+		},
+		{
+			"xs |> map(f) |> filter(g)",
+			"((xs |> map(f)) |> filter(g))",
+		},
+		{
+			"a + 1 |> f ?? g",
+			"((a + 1) |> (f ?? g))",
+		},
+		{
+			"xs |> len ? a : b",
+			"((xs |> len) ? a : b)",
+		},
+		{
+			"a ** b ** c",
+			"(a ** (b ** c))",
+		},
+		{
+			"-a ** b",
+			"(-(a ** b))",
+		},
+		{
+			"a ** -b * c",
+			"((a ** (-b)) * c)",
+		},
+		{
+			"a * b ** c[0]",
+			"(a * (b ** (c[0])))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -704,6 +1014,10 @@ func TestFunctionParameterParsing(t *testing.T) {
 		{input: "fn() {};", expectedParams: []string{}},
 		{input: "fn(x) {};", expectedParams: []string{"x"}},
 		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+		{input: "() => {};", expectedParams: []string{}},
+		{input: "x => x;", expectedParams: []string{"x"}},
+		{input: "(x) => x;", expectedParams: []string{"x"}},
+		{input: "(x, y, z) => { x };", expectedParams: []string{"x", "y", "z"}},
 	}
 
 	for _, tt := range tests {
@@ -726,6 +1040,79 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestNamedFunctionParsing(t *testing.T) {
+	input := `fn add(a, b) { a + b }; let f = fn fib(n) { n }; fn(x) { x };`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+	}
+
+	statement, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.FunctionStatement. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, statement.Function.Name, "add") {
+		return
+	}
+	if len(statement.Function.Parameters) != 2 {
+		t.Errorf("wrong number of parameters. got=%d", len(statement.Function.Parameters))
+	}
+
+	let := program.Statements[1].(*ast.LetStatement)
+	literal, ok := let.Value.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("let.Value is not *ast.FunctionLiteral. got=%T", let.Value)
+	}
+	if !testIdentifier(t, literal.Name, "fib") {
+		return
+	}
+
+	anonymous := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if anonymous.Name != nil {
+		t.Errorf("anonymous function has a name. got=%s", anonymous.Name)
+	}
+
+	expected := "fn add(a, b){(a + b)}let f = fn fib(n){n};fn(x){x}"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestArrowFunctionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x => x * 2", "fn(x){(x * 2)}"},
+		{"(a, b) => { let c = a + b; c }", "fn(a, b){let c = (a + b);c}"},
+		{"() => 1", "fn(){1}"},
+		{"map(xs, x => x + 1)", "map(xs, fn(x){(x + 1)})"},
+		{"reduce(xs, (acc, x) => acc + x, 0)", "reduce(xs, fn(acc, x){(acc + x)}, 0)"},
+		{"x => y => x + y", "fn(x){fn(y){(x + y)}}"},
+		{"xs |> map(x => x)", "(xs |> map(fn(x){x}))"},
+		{"(a) + b", "(a + b)"},
+		{"(a + b) * c", "((a + b) * c)"},
+		{"(x => x)(1)", "fn(x){x}(1)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -841,6 +1228,42 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"arr[1:3]", "(arr[1:3])"},
+		{"arr[:n]", "(arr[:n])"},
+		{"arr[1:]", "(arr[1:])"},
+		{"arr[:]", "(arr[:])"},
+		{"arr[::2]", "(arr[::2])"},
+		{"arr[1::2]", "(arr[1::2])"},
+		{"arr[a + 1:len(arr) - 1:-1]", "(arr[(a + 1):(len(arr) - 1):(-1)])"},
+		{"arr[-1]", "(arr[(-1)])"},
+		{"\"monkey\"[1:3][0]", "((\"monkey\"[1:3])[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	l := lexer.New("arr[1:2")
+	p := New(l)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected a parser error for an unclosed slice")
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "let" {
 		t.Errorf("s.TokenLiteral not 'let'. got=%q", s.TokenLiteral())
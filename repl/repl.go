@@ -1,49 +1,114 @@
 package repl
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
-	"github.com/ManuelGarciaF/go-interpreter/evaluator"
-	"github.com/ManuelGarciaF/go-interpreter/lexer"
+	"github.com/ManuelGarciaF/go-interpreter/interpreter"
 	"github.com/ManuelGarciaF/go-interpreter/object"
-	"github.com/ManuelGarciaF/go-interpreter/parser"
 
 	"github.com/chzyer/readline"
 )
 
+const prompt = "> "
+
 func Start(in io.ReadCloser, out io.Writer) {
+	// readline reads in on its own goroutine, so scripts read their input through it
+	// instead of from in directly.
+	stdin := &readlineStdin{}
+	interp := interpreter.New(
+		interpreter.WithStdin(stdin),
+		interpreter.WithStdout(out),
+		interpreter.WithFSPolicy(&object.FSPolicy{Roots: []string{"/"}}),
+	)
+
 	rl, err := readline.NewEx(&readline.Config{
-		Prompt: "> ",
-		Stdin: in,
-		Stdout: out,
+		Prompt:       prompt,
+		Stdin:        in,
+		Stdout:       out,
+		AutoComplete: &builtinCompleter{builtins: interp.Builtins()},
 	})
 	if err != nil {
 		panic(err)
 	}
 	defer rl.Close()
-
-	env := object.NewEnvironment()
+	stdin.rl = rl
 
 	for {
 		line, err := rl.Readline()
 		if err != nil { // EOF or interrupt
 			break
 		}
-		l := lexer.New(line)
-		p := parser.New(l)
 
-		program := p.ParseProgram()
-		if len(p.Errors()) > 0 {
-			for _, msg := range p.Errors() {
+		evaluated, err := interp.Eval(line)
+		var parseErr *interpreter.ParseError
+		var runtimeErr *interpreter.RuntimeError
+		switch {
+		case errors.As(err, &parseErr):
+			for _, msg := range parseErr.Messages {
 				fmt.Fprintf(out, "\t%s\n", msg)
 			}
-			continue
+		case errors.As(err, &runtimeErr):
+			fmt.Fprintln(out, runtimeErr.Object.Inspect())
+			if trace := runtimeErr.Object.StackTrace(); trace != "" {
+				fmt.Fprintln(out, trace)
+			}
+		case evaluated != nil:
+			fmt.Fprintln(out, evaluated.Inspect())
 		}
+	}
+}
 
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
-			fmt.Fprintln(out, evaluated.Inspect())
+// Reads the lines typed while a script is running, for readLine, input and readAll.
+type readlineStdin struct {
+	rl      *readline.Instance
+	pending []byte // Rest of the last line, not read yet
+}
+
+func (s *readlineStdin) Read(p []byte) (int, error) {
+	if len(s.pending) == 0 {
+		// The script prints its own prompts.
+		s.rl.SetPrompt("")
+		line, err := s.rl.Readline()
+		s.rl.SetPrompt(prompt)
+		if err != nil { // EOF or interrupt
+			return 0, io.EOF
 		}
+		s.pending = []byte(line + "\n")
 	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Completes the word under the cursor with the names of the registered builtins.
+type builtinCompleter struct {
+	builtins *object.BuiltinRegistry
+}
+
+func (c *builtinCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	// Find the start of the word being typed, namespaces included.
+	start := pos
+	for start > 0 && isWordRune(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:pos])
+
+	candidates := make([][]rune, 0)
+	for _, name := range c.builtins.Names() {
+		if strings.HasPrefix(name, prefix) {
+			// readline expects only the missing suffix.
+			candidates = append(candidates, []rune(name[len(prefix):]))
+		}
+	}
+
+	return candidates, len([]rune(prefix))
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '.' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
 }
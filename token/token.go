@@ -5,6 +5,9 @@ type TokenType uint8
 type Token struct {
 	Type    TokenType
 	Literal string
+	// Where the token starts in the source, both starting from 1. Columns count bytes.
+	Line   int
+	Column int
 }
 
 // Token types
@@ -17,6 +20,12 @@ const (
 	IDENTIFIER
 	INT
 	STRING
+	// Interpolated strings are split around their ${} expressions
+	STRING_HEAD      // From the opening '"' up to the first "${"
+	STRING_MIDDLE    // Between a '}' and the next "${"
+	STRING_TAIL      // From the last '}' up to the closing '"'
+	RAW_STRING       // Between backticks, no interpolation
+	MULTILINE_STRING // Between triple quotes, with the common indentation removed
 
 	// Operators
 	ASSIGN
@@ -24,11 +33,26 @@ const (
 	MINUS
 	BANG
 	ASTERISK
+	POWER // **
 	SLASH
 
 	LT
 	GT
 
+	// Bitwise operators
+	AMPERSAND   // &
+	PIPE        // |
+	CARET       // ^
+	TILDE       // ~
+	SHIFT_LEFT  // <<
+	SHIFT_RIGHT // >>
+
+	QUESTION       // ?
+	NULL_COALESCE  // ??
+	OPTIONAL_CHAIN // ?.
+	PIPE_FORWARD   // |>
+	ARROW          // =>
+
 	// Delimiters
 	COMMA
 	SEMICOLON
@@ -44,6 +68,7 @@ const (
 	// Keywords
 	FUNCTION
 	LET
+	CONST
 	IF
 	ELSE
 	RETURN
@@ -55,37 +80,55 @@ const (
 
 // For pretty printing the enum values
 var tokenTypeStrings = map[TokenType]string{
-	ILLEGAL:    "ILLEGAL",
-	EOF:        "EOF",
-	IDENTIFIER: "IDENTIFIER",
-	INT:        "INT",
-	STRING:     "STRING",
-	ASSIGN:     "ASSIGN",
-	PLUS:       "PLUS",
-	MINUS:      "MINUS",
-	BANG:       "BANG",
-	ASTERISK:   "ASTERISK",
-	SLASH:      "SLASH",
-	LT:         "LT",
-	GT:         "GT",
-	COMMA:      "COMMA",
-	SEMICOLON:  "SEMICOLON",
-	COLON:      "COLON",
-	LPAREN:     "LPAREN",
-	RPAREN:     "RPAREN",
-	LBRACE:     "LBRACE",
-	RBRACE:     "RBRACE",
-	LBRACKET:   "LBRACKET",
-	RBRACKET:   "RBRACKET",
-	FUNCTION:   "FUNCTION",
-	LET:        "LET",
-	IF:         "IF",
-	ELSE:       "ELSE",
-	RETURN:     "RETURN",
-	TRUE:       "TRUE",
-	FALSE:      "FALSE",
-	EQ:         "EQ",
-	NOT_EQ:     "NOT_EQ",
+	ILLEGAL:          "ILLEGAL",
+	EOF:              "EOF",
+	IDENTIFIER:       "IDENTIFIER",
+	INT:              "INT",
+	STRING:           "STRING",
+	STRING_HEAD:      "STRING_HEAD",
+	STRING_MIDDLE:    "STRING_MIDDLE",
+	STRING_TAIL:      "STRING_TAIL",
+	RAW_STRING:       "RAW_STRING",
+	MULTILINE_STRING: "MULTILINE_STRING",
+	ASSIGN:           "ASSIGN",
+	PLUS:             "PLUS",
+	MINUS:            "MINUS",
+	BANG:             "BANG",
+	ASTERISK:         "ASTERISK",
+	POWER:            "POWER",
+	SLASH:            "SLASH",
+	LT:               "LT",
+	GT:               "GT",
+	AMPERSAND:        "AMPERSAND",
+	PIPE:             "PIPE",
+	CARET:            "CARET",
+	TILDE:            "TILDE",
+	SHIFT_LEFT:       "SHIFT_LEFT",
+	SHIFT_RIGHT:      "SHIFT_RIGHT",
+	QUESTION:         "QUESTION",
+	NULL_COALESCE:    "NULL_COALESCE",
+	OPTIONAL_CHAIN:   "OPTIONAL_CHAIN",
+	PIPE_FORWARD:     "PIPE_FORWARD",
+	ARROW:            "ARROW",
+	COMMA:            "COMMA",
+	SEMICOLON:        "SEMICOLON",
+	COLON:            "COLON",
+	LPAREN:           "LPAREN",
+	RPAREN:           "RPAREN",
+	LBRACE:           "LBRACE",
+	RBRACE:           "RBRACE",
+	LBRACKET:         "LBRACKET",
+	RBRACKET:         "RBRACKET",
+	FUNCTION:         "FUNCTION",
+	LET:              "LET",
+	CONST:            "CONST",
+	IF:               "IF",
+	ELSE:             "ELSE",
+	RETURN:           "RETURN",
+	TRUE:             "TRUE",
+	FALSE:            "FALSE",
+	EQ:               "EQ",
+	NOT_EQ:           "NOT_EQ",
 }
 
 func (tt TokenType) String() string {
@@ -93,12 +136,13 @@ func (tt TokenType) String() string {
 }
 
 func New(tt TokenType, l string) Token {
-	return Token{tt, l}
+	return Token{Type: tt, Literal: l}
 }
 
 var keywords = map[string]TokenType{
 	"fn":     FUNCTION,
 	"let":    LET,
+	"const":  CONST,
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,